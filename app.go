@@ -2,220 +2,652 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"github.com/zwoabier/youtrack-helper/internal/logger"
+	"github.com/zwoabier/youtrack-helper/internal/query"
+	"github.com/zwoabier/youtrack-helper/internal/search"
 )
 
-// #region debug instrumentation
-const debugLogPath = "/home/menzelm/Projects/youtrack-helper/.cursor/debug.log"
-
-func writeDebugND(location, message string, data map[string]interface{}, hypothesisId string) {
-	payload := map[string]interface{}{
-		"sessionId":    "debug-session",
-		"runId":        "run1",
-		"hypothesisId": hypothesisId,
-		"location":     location,
-		"message":      message,
-		"data":         data,
-		"timestamp":    time.Now().UnixMilli(),
-	}
-	b, _ := json.Marshal(payload)
-	f, err := os.OpenFile(debugLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return
-	}
-	f.Write(b)
-	f.Write([]byte("\n"))
-	f.Close()
-}
-
-// #endregion
 // WailsApp struct
 type App struct {
-	ctx     context.Context
-	config  Config
-	tickets []Ticket
-	cm      *ConfigManager
-	ytAPI   *YouTrackAPI
+	ctx   context.Context
+	store *TicketStore
+
+	// configMu guards config, which the scheduler's syncs and the UI's
+	// bindings read and write from different goroutines
+	configMu sync.RWMutex
+	config   Config
+
+	cm    *ConfigManager
+	ytAPI *YouTrackAPI
+
+	// syncCtx is cancelled on shutdown so in-flight syncs stop between pages
+	syncCtx    context.Context
+	cancelSync context.CancelFunc
+	// syncMu serializes syncs started by the scheduler and by the UI
+	syncMu    sync.Mutex
+	scheduler *syncScheduler
+	changes   *changeLog
+	usage     *usageStore
+	// instanceDir holds the ticket cache, change log and usage history of the configured instance
+	instanceDir string
+	cache       ticketCache
+	cacheMu     sync.RWMutex // guards swapping cache
+
+	// fullSyncRequired is set when the loaded cache can't serve as a delta base
+	fullSyncRequired bool
+
+	// search indexes the store for SearchTickets; kept current by indexTickets
+	search *search.Index
+	// customFields lists the custom fields queries can refer to
+	customFields customFieldKinds
+
+	// cancelRemote aborts the running SearchRemote request
+	remoteMu     sync.Mutex
+	cancelRemote context.CancelFunc
+
+	meMu      sync.Mutex
+	me        string    // login of the configured token's user; see currentLogin
+	meFetched time.Time // last attempt to fetch me
+	meGen     int       // bumped when the instance changes, discarding fetches in flight
 }
 
+// SearchRemote fetches only a few issues; it complements the local cache
+// rather than replacing it
+const (
+	remoteSearchLimit   = 10
+	remoteSearchTimeout = 15 * time.Second
+)
+
+// Fetching the user "me" in queries refers to
+const (
+	currentUserTimeout = 10 * time.Second
+	currentUserRetry   = time.Minute // wait after a failed fetch
+)
+
 // NewApp creates a new App application struct
 func NewApp() *App {
 	cm := NewConfigManager()
-	return &App{
-		cm:    cm,
-		ytAPI: NewYouTrackAPI(cm),
+	store := NewTicketStore()
+	a := &App{
+		cm:      cm,
+		store:   store,
+		ytAPI:   NewYouTrackAPI(cm, store),
+		changes: &changeLog{},
+		usage:   &usageStore{},
+		search:  search.NewIndex(),
 	}
+	a.scheduler = newSyncScheduler(a.scheduledSync, a.syncInterval)
+	// Subscribe before anything is loaded so the index sees every ticket
+	storeChanges, _ := store.Subscribe()
+	go a.indexTickets(storeChanges)
+	return a
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.syncCtx, a.cancelSync = context.WithCancel(ctx)
 
 	// Use config from ConfigManager (same source YouTrackAPI uses)
-	a.config = a.cm.GetConfig()
+	cfg := a.cm.GetConfig()
+	a.configMu.Lock()
+	a.config = cfg
+	a.configMu.Unlock()
 
 	// Logger: config first, then env overrides level
-	level := a.config.LogLevel
+	level := cfg.LogLevel
 	if level == "" {
 		level = "debug"
 	}
 	logger.SetLevel(level)
-	logger.SetLogToFile(a.config.LogToFile)
+	logger.SetLogToFile(cfg.LogToFile)
 	if env := os.Getenv("YOUTRACK_HELPER_LOG"); env != "" {
 		logger.SetLevel(env)
 	}
 
-	// Try to load tickets from cache
-	if err := a.loadTicketsFromCache(); err != nil {
-		logger.Warn("loading tickets from cache: %v", err)
-	} else {
-		logger.Debug("loaded %d cached tickets", len(a.tickets))
-	}
+	// Load cached tickets and change log of the configured instance
+	a.search.SetNormalization(searchNormalization(cfg))
+	a.openInstance()
 
-	// Debug log: startup config state (H1)
-	writeDebugND("app.go:startup", "startup_config", map[string]interface{}{
-		"isConfigured":       a.cm.IsConfigured(),
-		"configProjectsLen":  len(a.config.Projects),
-		"hasToken":           a.cm.GetToken() != "",
-		"configBaseURLEmpty": a.config.BaseURL == "",
-	}, "H1")
+	// Scheduled syncs are skipped until setup is complete
+	if !a.cm.IsConfigured() {
+		logger.Info("Background sync waiting: configuration not complete")
+	}
+	a.scheduler.Start(a.syncCtx)
+}
 
-	// Start background sync only if configured
-	if a.cm.IsConfigured() {
-		go a.startBackgroundSync()
-	} else {
-		logger.Info("Background sync skipped: configuration not complete")
+// shutdown is called when the app is closing. It aborts any running sync.
+func (a *App) shutdown(ctx context.Context) {
+	if a.cancelSync != nil {
+		a.cancelSync()
+	}
+	// Wait for a running sync to wind down before closing its cache
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+	a.cacheMu.Lock()
+	defer a.cacheMu.Unlock()
+	if a.cache != nil {
+		if err := a.cache.Close(); err != nil {
+			logger.Warn("closing ticket cache: %v", err)
+		}
+		a.cache = nil
 	}
 }
 
-// saveConfig saves the current configuration via ConfigManager
-func (a *App) saveConfig() error {
+// currentConfig returns a snapshot of the configuration. Its slices and maps
+// are shared and must not be modified.
+func (a *App) currentConfig() Config {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.config
+}
+
+// updateConfig applies update to the configuration and saves it via
+// ConfigManager. The lock is held while saving, so concurrent updates reach
+// the disk in the order they were made.
+func (a *App) updateConfig(update func(c *Config)) error {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	update(&a.config)
 	return a.cm.SaveConfig(a.config)
 }
 
-// loadTicketsFromCache loads tickets from a local cache file
-func (a *App) loadTicketsFromCache() error {
-	data, err := ioutil.ReadFile("tickets_cache.json")
-	if err != nil {
-		return fmt.Errorf("failed to read tickets_cache.json: %w", err)
+// scheduledSync is run by the sync scheduler; it is a no-op until setup is complete
+func (a *App) scheduledSync() error {
+	if !a.cm.IsConfigured() {
+		logger.Debug("Background sync skipped: configuration not complete")
+		return nil
 	}
-	return json.Unmarshal(data, &a.tickets)
+	_, err := a.SyncTickets()
+	return err
 }
 
-// saveTicketsToCache saves tickets to a local cache file
-func (a *App) saveTicketsToCache() error {
-	data, err := json.MarshalIndent(a.tickets, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal tickets: %w", err)
+// syncInterval returns the configured time between background syncs
+func (a *App) syncInterval() time.Duration {
+	if minutes := a.currentConfig().SyncIntervalMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
 	}
-	return ioutil.WriteFile("tickets_cache.json", data, 0644)
+	return defaultSyncInterval
 }
 
-// startBackgroundSync initiates an asynchronous fetch to YouTrack REST API
-func (a *App) startBackgroundSync() {
-	// Implement periodic sync logic here
-	// For now, a simple one-time sync after startup
-	time.Sleep(5 * time.Second) // Simulate some delay
-	_, err := a.SyncTickets()
-	if err != nil {
-		logger.Error("initial background sync failed: %v", err)
-	}
+// PauseSync stops background syncs until ResumeSync is called
+func (a *App) PauseSync() {
+	a.scheduler.Pause()
+}
+
+// ResumeSync restarts background syncs and syncs right away
+func (a *App) ResumeSync() {
+	a.scheduler.Resume()
+}
+
+// TriggerSync asks the scheduler for an immediate background sync
+func (a *App) TriggerSync() {
+	a.scheduler.Trigger()
+}
+
+// GetSyncStatus returns the background sync scheduler state
+func (a *App) GetSyncStatus() SyncStatus {
+	return a.scheduler.Status()
+}
+
+// GetAPIStats returns client-side API throttling counters
+func (a *App) GetAPIStats() APIStats {
+	return APIStats{DelayedRequests: a.ytAPI.DelayedRequests()}
 }
 
 // GetConfig returns the current application configuration
 func (a *App) GetConfig() Config {
-	return a.config
+	return a.currentConfig()
 }
 
 // SaveConfig saves the provided configuration
 func (a *App) SaveConfig(c Config) error {
-	a.config = c
+	// Reject connection settings that can't be applied, e.g. a missing CA bundle
+	if err := a.ytAPI.ApplyConfig(c); err != nil {
+		return err
+	}
+	var switchInstance bool
+	err := a.updateConfig(func(old *Config) {
+		// Sync bookkeeping is owned by the backend; a changed instance or
+		// project set invalidates it so the next sync is a full one.
+		if sameSyncScope(*old, c) {
+			c.LastSyncTime = old.LastSyncTime
+			c.LastFullSyncTime = old.LastFullSyncTime
+		} else {
+			c.LastSyncTime = 0
+			c.LastFullSyncTime = 0
+		}
+		switchInstance = normalizeBaseURL(old.BaseURL) != normalizeBaseURL(c.BaseURL) ||
+			old.CacheBackend != c.CacheBackend
+		*old = c
+	})
+	a.search.SetNormalization(searchNormalization(c))
+	if switchInstance {
+		a.meMu.Lock()
+		a.me, a.meFetched = "", time.Time{}
+		a.meGen++
+		a.meMu.Unlock()
+		a.syncMu.Lock()
+		a.openInstance()
+		a.syncMu.Unlock()
+	}
 	level := c.LogLevel
 	if level == "" {
 		level = "info"
 	}
 	logger.SetLevel(level)
 	logger.SetLogToFile(c.LogToFile)
-	return a.cm.SaveConfig(c)
+	return err
 }
 
-// GetTickets returns cached tickets instantly
+// GetTickets returns all cached tickets at once from memory. Large caches are
+// better read with GetTicketsPage.
 func (a *App) GetTickets() []Ticket {
-	// Debug: publish ticket count and sample
-	func() {
-		sample := []string{}
-		for i, t := range a.tickets {
-			if i >= 5 {
-				break
-			}
-			sample = append(sample, t.ID)
+	return a.store.Snapshot()
+}
+
+// SearchTickets returns the limit best matches for q (defaultSearchLimit if
+// limit <= 0) together with any syntax errors in q. Free text is ranked by the
+// search index; YouTrack query terms like "Type: Bug #Unresolved for: me"
+// filter the cached tickets and "sort by: updated" orders them. A query
+// without free text lists the newest tickets.
+func (a *App) SearchTickets(q string, limit int) SearchResponse {
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	parsed := query.Parse(q, queryFieldsFor(newFieldMapping(a.currentConfig().FieldMapping), &a.customFields))
+	searchLimit := limit
+	if parsed.Filtered() || parsed.Sorted() {
+		searchLimit = 0
+	}
+	env := query.Env{Me: sync.OnceValue(a.currentLogin)}
+
+	results := []SearchResult{}
+	for _, h := range a.search.Search(parsed.Text, searchLimit, a.usage.Boost(time.Now())) {
+		if len(results) == limit && !parsed.Sorted() {
+			break
 		}
-		payload := map[string]interface{}{
-			"sessionId":    "debug-session",
-			"runId":        "run1",
-			"hypothesisId": "H4",
-			"location":     "app.go:GetTickets",
-			"message":      "get_tickets_called",
-			"data": map[string]interface{}{
-				"count":  len(a.tickets),
-				"sample": sample,
-			},
-			"timestamp": time.Now().UnixMilli(),
+		t, ok := a.store.Get(h.ID)
+		if !ok {
+			// Removed since the index was searched
+			continue
 		}
-		b, _ := json.Marshal(payload)
-		f, _ := os.OpenFile(debugLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if f != nil {
-			f.Write(b)
-			f.Write([]byte("\n"))
-			f.Close()
+		if !parsed.Match(ticketRecord{t}, env) {
+			continue
 		}
-	}()
+		results = append(results, SearchResult{Ticket: t, Score: h.Score, Highlights: h.Highlights, Cached: true})
+	}
+	if parsed.Sorted() {
+		// The sort overrides relevance; equal tickets keep their ranking
+		sort.SliceStable(results, func(i, j int) bool {
+			return parsed.Compare(ticketRecord{results[i].Ticket}, ticketRecord{results[j].Ticket}) < 0
+		})
+		results = results[:min(len(results), limit)]
+	}
+	return SearchResponse{Results: results, Errors: parsed.Errors}
+}
 
-	return a.tickets
+// SearchRemote runs q against YouTrack and appends its hits to the local
+// results of SearchTickets; hits outside the local cache are marked as not
+// cached. Each call cancels the previous one, so the frontend can call it on
+// every debounced keystroke; a superseded call fails with "Search cancelled.".
+func (a *App) SearchRemote(q string) (SearchResponse, error) {
+	ctx, cancel := a.startRemoteSearch()
+	defer cancel()
+
+	resp := a.SearchTickets(q, defaultSearchLimit)
+	if strings.TrimSpace(q) == "" {
+		return resp, nil
+	}
+	tickets, err := a.ytAPI.SearchIssues(ctx, q, remoteSearchLimit)
+	if err != nil {
+		return resp, err
+	}
+	listed := make(map[string]bool, len(resp.Results))
+	for _, r := range resp.Results {
+		listed[r.Ticket.ID] = true
+	}
+	for _, t := range tickets {
+		if listed[t.ID] {
+			continue
+		}
+		_, cached := a.store.Get(t.ID)
+		resp.Results = append(resp.Results, SearchResult{Ticket: t, Cached: cached})
+	}
+	return resp, nil
 }
 
-// FrontendLog allows the frontend to write a debug entry to the NDJSON debug log.
-func (a *App) FrontendLog(message string, data map[string]interface{}) {
-	// Perform log write asynchronously to avoid blocking the UI/renderer
-	go func(msg string, d map[string]interface{}) {
-		payload := map[string]interface{}{
-			"sessionId": "debug-session",
-			"runId":     "run1",
-			"location":  "frontend",
-			"message":   msg,
-			"data":      d,
-			"timestamp": time.Now().UnixMilli(),
+// startRemoteSearch cancels the running remote search and returns the
+// context for a new one, which also ends on shutdown
+func (a *App) startRemoteSearch() (context.Context, context.CancelFunc) {
+	a.remoteMu.Lock()
+	defer a.remoteMu.Unlock()
+	if a.cancelRemote != nil {
+		a.cancelRemote()
+	}
+	parent := a.syncCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, remoteSearchTimeout)
+	a.cancelRemote = cancel
+	return ctx, cancel
+}
+
+// currentLogin returns the login of the configured token's user, fetching it
+// once per instance. It returns "" while the user can't be fetched, including
+// to callers arriving while another one fetches it; meMu is never held during
+// the request, so searches don't queue up behind it.
+func (a *App) currentLogin() string {
+	a.meMu.Lock()
+	if a.me != "" || time.Since(a.meFetched) < currentUserRetry || !a.cm.IsConfigured() {
+		me := a.me
+		a.meMu.Unlock()
+		return me
+	}
+	a.meFetched = time.Now()
+	gen := a.meGen
+	a.meMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), currentUserTimeout)
+	defer cancel()
+	u, err := a.ytAPI.GetCurrentUser(ctx, a.currentConfig().BaseURL, a.cm.GetToken())
+	if err != nil {
+		logger.Debug("resolving \"me\" for search: %v", err)
+		return ""
+	}
+	a.meMu.Lock()
+	defer a.meMu.Unlock()
+	if gen != a.meGen {
+		// The instance changed meanwhile; this login belongs to the old one
+		return ""
+	}
+	a.me = u.Login
+	return a.me
+}
+
+// indexTickets applies store changes to the search index until changes is
+// closed. A reset rebuilds the index from the store.
+func (a *App) indexTickets(changes <-chan StoreChange) {
+	for change := range changes {
+		if change.Reset {
+			tickets := a.store.Snapshot()
+			docs := make([]search.Document, len(tickets))
+			for i, t := range tickets {
+				docs[i] = searchDocument(t)
+				a.customFields.add(t)
+			}
+			a.search.Reset(docs)
+			continue
 		}
-		b, _ := json.Marshal(payload)
-		f, err := os.OpenFile(debugLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if err == nil {
-			// Best-effort write; ignore errors to keep this non-blocking
-			_, _ = f.Write(b)
-			_, _ = f.Write([]byte("\n"))
-			_ = f.Close()
+		ids := append(append([]string{}, change.Added...), change.Updated...)
+		docs := make([]search.Document, 0, len(ids))
+		for _, id := range ids {
+			if t, ok := a.store.Get(id); ok {
+				docs = append(docs, searchDocument(t))
+				a.customFields.add(t)
+			}
 		}
-	}(message, data)
+		a.search.Upsert(docs...)
+		a.search.Delete(change.Removed...)
+	}
+}
+
+// searchNormalization returns the search normalization configured in c
+func searchNormalization(c Config) search.Normalization {
+	return search.Normalization{
+		FoldUmlauts:     !c.DisableUmlautFolding,
+		StripDiacritics: !c.DisableDiacriticStripping,
+		Stem:            !c.DisableStemming,
+		SplitCompounds:  !c.DisableCompoundSplitting,
+	}
 }
 
-// SyncTickets forces a network sync with YouTrack API
+// searchDocument returns the searchable fields of t
+func searchDocument(t Ticket) search.Document {
+	return search.Document{ID: t.ID, Summary: t.Summary, Type: t.Type, Priority: t.Priority, Sprints: t.Sprints}
+}
+
+// GetTicketsBy returns the cached tickets whose field ("project", "type",
+// "priority" or "sprint") equals value. The database backend answers from its
+// indexes; otherwise the in-memory cache is scanned.
+func (a *App) GetTicketsBy(field, value string) ([]Ticket, error) {
+	a.cacheMu.RLock()
+	db, ok := a.cache.(*boltTicketCache)
+	if ok {
+		defer a.cacheMu.RUnlock()
+		return db.Query(field, value)
+	}
+	a.cacheMu.RUnlock()
+
+	var get func(Ticket) []string
+	for _, idx := range boltIndexes {
+		if idx.field == field {
+			get = idx.values
+		}
+	}
+	if get == nil {
+		return nil, fmt.Errorf("Unknown field %q.", field)
+	}
+	result := []Ticket{}
+	for _, t := range a.store.Snapshot() {
+		for _, v := range get(t) {
+			if v == value {
+				result = append(result, t)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// GetTicketsPage returns the cached tickets a page of limit tickets at a time
+// (defaultTicketPageSize if limit <= 0), ordered by ID. cursor is "" for the
+// first page and the previous page's Next after that. The database backend
+// streams each page from disk without building the whole list; otherwise the
+// page is cut from the in-memory store.
+func (a *App) GetTicketsPage(cursor string, limit int) (TicketPage, error) {
+	if limit <= 0 {
+		limit = defaultTicketPageSize
+	}
+	a.cacheMu.RLock()
+	db, ok := a.cache.(*boltTicketCache)
+	if ok {
+		defer a.cacheMu.RUnlock()
+		tickets, next, err := db.Page(cursor, limit)
+		return TicketPage{Tickets: tickets, Next: next}, err
+	}
+	a.cacheMu.RUnlock()
+
+	var rest []Ticket
+	for _, t := range a.store.Snapshot() {
+		if t.ID > cursor {
+			rest = append(rest, t)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].ID < rest[j].ID })
+	page := TicketPage{Tickets: []Ticket{}}
+	if len(rest) > limit {
+		rest = rest[:limit]
+		page.Next = rest[limit-1].ID
+	}
+	page.Tickets = append(page.Tickets, rest...)
+	return page, nil
+}
+
+// FrontendLog writes a debug entry from the frontend to the app log.
+func (a *App) FrontendLog(message string, data map[string]interface{}) {
+	logger.Debug("frontend: %s %v", message, data)
+}
+
+// defaultFullSyncInterval is used when Config.FullSyncIntervalHours is unset.
+const defaultFullSyncInterval = 24 * time.Hour
+
+// deltaSyncOverlap is subtracted from LastSyncTime so clock skew between us and
+// YouTrack, and minute-granular timestamps, can't drop an update.
+const deltaSyncOverlap = 2 * time.Minute
+
+// SyncTickets forces a network sync with YouTrack API.
+// It only fetches issues updated since the last sync unless a full resync is due.
 func (a *App) SyncTickets() ([]Ticket, error) {
-	logger.Info("Syncing tickets from YouTrack API...")
-	if err := a.ytAPI.SyncTickets(a.ctx); err != nil {
+	return a.syncTickets(false)
+}
+
+// FullResync re-downloads every issue, dropping deleted and moved tickets from the cache
+func (a *App) FullResync() ([]Ticket, error) {
+	return a.syncTickets(true)
+}
+
+// sameSyncScope reports whether two configs sync the same instance and
+// projects and parse tickets the same way
+func sameSyncScope(a, b Config) bool {
+	if normalizeBaseURL(a.BaseURL) != normalizeBaseURL(b.BaseURL) || len(a.Projects) != len(b.Projects) {
+		return false
+	}
+	if !reflect.DeepEqual(newFieldMapping(a.FieldMapping), newFieldMapping(b.FieldMapping)) {
+		return false
+	}
+	for i := range a.Projects {
+		if a.Projects[i] != b.Projects[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// needsFullSync reports whether a delta sync of cfg can't be trusted: nothing
+// cached yet, no previous sync, a stale or invalidated cache, or the full
+// resync interval has elapsed.
+func (a *App) needsFullSync(cfg Config) bool {
+	if a.fullSyncRequired || cfg.LastSyncTime == 0 || cfg.LastFullSyncTime == 0 || a.store.Len() == 0 {
+		return true
+	}
+	interval := defaultFullSyncInterval
+	if cfg.FullSyncIntervalHours > 0 {
+		interval = time.Duration(cfg.FullSyncIntervalHours) * time.Hour
+	}
+	return time.Since(time.Unix(cfg.LastFullSyncTime, 0)) >= interval
+}
+
+// syncTickets runs a delta sync, or a full one when forced or due, and
+// persists the result. Only one sync runs at a time; concurrent callers wait
+// for the running one.
+func (a *App) syncTickets(forceFull bool) ([]Ticket, error) {
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+
+	cfg := a.currentConfig()
+	full := forceFull || a.needsFullSync(cfg)
+	a.emit(EventSyncStarted, SyncStarted{Full: full})
+	opts := SyncOptions{
+		Progress: func(p SyncProgress) {
+			logger.Info("Sync progress: page %d, %d tickets fetched", p.Page, p.Fetched)
+			a.emit(EventSyncProgress, p)
+		},
+	}
+	if full {
+		logger.Info("Syncing tickets from YouTrack API (full resync)...")
+	} else {
+		opts.Since = time.Unix(cfg.LastSyncTime, 0).Add(-deltaSyncOverlap)
+		logger.Info("Syncing tickets from YouTrack API (delta)...")
+	}
+
+	// Record the start time so issues updated while we page aren't skipped next time
+	started := time.Now()
+	prev := a.store.Snapshot()
+	result, err := a.ytAPI.SyncTickets(a.syncCtx, opts)
+	change := result.Change
+	if err != nil {
+		// Tickets streamed in before the failure are already in the store
+		if !change.Empty() {
+			a.recordChange(prev, change, started)
+		}
+		a.emit(EventSyncFailed, SyncFailed{Full: full, Message: err.Error()})
 		return nil, err
 	}
-	a.tickets = a.ytAPI.GetCachedTickets()
-	a.config.LastSyncTime = time.Now().Unix()
-	_ = a.saveConfig()
-	_ = a.saveTicketsToCache()
-	return a.tickets, nil
+	// After a partial failure keep the old sync times, so the next delta
+	// sync also covers the projects that failed this time
+	if !result.Partial() {
+		if full {
+			a.fullSyncRequired = false
+		}
+		err := a.updateConfig(func(c *Config) {
+			// SaveConfig may have switched projects meanwhile; this sync
+			// says nothing about the new ones
+			if !sameSyncScope(cfg, *c) {
+				return
+			}
+			c.LastSyncTime = started.Unix()
+			if full {
+				c.LastFullSyncTime = started.Unix()
+			}
+		})
+		if err != nil {
+			logger.Warn("saving sync times: %v", err)
+		}
+	}
+	a.recordChange(prev, change, started)
+	a.emit(EventSyncCompleted, SyncCompleted{
+		Full:     full,
+		Added:    len(change.Added),
+		Updated:  len(change.Updated),
+		Removed:  len(change.Removed),
+		Total:    a.store.Len(),
+		Projects: result.Projects,
+	})
+	return a.store.Snapshot(), nil
+}
+
+// recordChange persists a sync's store change to the ticket cache and the
+// change log; prev is the store snapshot taken before the sync
+func (a *App) recordChange(prev []Ticket, change StoreChange, at time.Time) {
+	if err := a.saveTicketsToCache(change); err != nil {
+		logger.Warn("saving ticket cache: %v", err)
+	}
+	// The initial download isn't a change worth logging
+	if len(prev) > 0 && !change.Empty() {
+		if err := a.changes.Append(diffTickets(prev, a.store.Snapshot(), at)); err != nil {
+			logger.Warn("saving change log: %v", err)
+		}
+	}
+}
+
+// emit sends a Wails runtime event to the frontend. It is a no-op before startup.
+func (a *App) emit(name string, data interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data)
+}
+
+// RecordUsage records that the ticket was copied, opened or selected
+// (UsageCopy, UsageOpen or UsageSelect), which ranks it higher in searches
+func (a *App) RecordUsage(ticketID, action string) error {
+	if err := a.usage.Record(ticketID, action, time.Now()); err != nil {
+		logger.Warn("recording usage of %s: %v", ticketID, err)
+		return err
+	}
+	return nil
+}
+
+// ClearUsageHistory forgets which tickets were used
+func (a *App) ClearUsageHistory() error {
+	return a.usage.Clear()
+}
+
+// GetRecentChanges returns ticket changes seen by syncs since the given unix time, newest first
+func (a *App) GetRecentChanges(since int64) []TicketChange {
+	return a.changes.Since(since)
 }
 
 // CopyToClipboard copies the given text to the clipboard
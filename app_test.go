@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/zwoabier/youtrack-helper/internal/youtracktest"
+)
+
+// newTestApp returns an App configured for srv as after startup, backed by a
+// mock keyring and a temporary home directory. The Wails runtime isn't
+// available, so no events are emitted.
+func newTestApp(t *testing.T, srv *youtracktest.Server, cfg Config) *App {
+	t.Helper()
+	keyring.MockInit()
+	t.Setenv("HOME", t.TempDir())
+
+	a := NewApp()
+	if err := a.cm.SaveToken(youtracktest.Token); err != nil {
+		t.Fatalf("saving token: %v", err)
+	}
+	cfg.BaseURL = srv.URL
+	if err := a.cm.SaveConfig(cfg); err != nil {
+		t.Fatalf("saving config: %v", err)
+	}
+	a.syncCtx, a.cancelSync = context.WithCancel(context.Background())
+	t.Cleanup(func() { a.shutdown(context.Background()) })
+	a.config = a.cm.GetConfig()
+	a.openInstance()
+	return a
+}
+
+func TestSaveConfigDuringScheduledSync(t *testing.T) {
+	srv := youtracktest.NewServer()
+	defer srv.Close()
+	a := newTestApp(t, srv, Config{Projects: []string{"AGV", "JU"}})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			if err := a.scheduledSync(); err != nil {
+				t.Errorf("scheduled sync: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 20; i++ {
+			c := a.GetConfig()
+			c.SyncIntervalMinutes = i
+			if err := a.SaveConfig(c); err != nil {
+				t.Errorf("saving config: %v", err)
+			}
+			_ = a.syncInterval()
+		}
+	}()
+	wg.Wait()
+
+	got := a.GetConfig()
+	if got.SyncIntervalMinutes != 20 {
+		t.Errorf("SyncIntervalMinutes = %d, want 20", got.SyncIntervalMinutes)
+	}
+	if got.LastSyncTime == 0 || got.LastFullSyncTime == 0 {
+		t.Errorf("sync times lost: %+v", got)
+	}
+	if n := a.store.Len(); n != 5 {
+		t.Errorf("store has %d tickets, want 5", n)
+	}
+
+	// The file must hold the latest config, not one overtaken by a slower write
+	data, err := os.ReadFile(filepath.Join(a.cm.ConfigDir(), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved, got) {
+		t.Errorf("saved config = %+v, want %+v", saved, got)
+	}
+}
+
+func TestGetTicketsPage(t *testing.T) {
+	for _, backend := range []string{CacheBackendJSON, CacheBackendBolt} {
+		t.Run("backend="+backend, func(t *testing.T) {
+			srv := youtracktest.NewServer()
+			defer srv.Close()
+			a := newTestApp(t, srv, Config{Projects: []string{"AGV", "JU"}, CacheBackend: backend})
+			if _, err := a.SyncTickets(); err != nil {
+				t.Fatal(err)
+			}
+
+			var pages [][]string
+			cursor := ""
+			for {
+				page, err := a.GetTicketsPage(cursor, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var ids []string
+				for _, tk := range page.Tickets {
+					ids = append(ids, tk.ID)
+				}
+				pages = append(pages, ids)
+				if page.Next == "" {
+					break
+				}
+				cursor = page.Next
+			}
+			want := [][]string{{"AGV-951", "AGV-952"}, {"AGV-953", "JU-17"}, {"JU-18"}}
+			if !reflect.DeepEqual(pages, want) {
+				t.Errorf("pages = %v, want %v", pages, want)
+			}
+		})
+	}
+}
+
+// TestSearchIndexFollowsStore loads the store faster than the indexer can
+// keep up, like loading a large database cache
+func TestSearchIndexFollowsStore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a := NewApp()
+	const batches, batch = 50, 1000
+	for b := 0; b < batches; b++ {
+		tickets := make([]Ticket, batch)
+		for i := range tickets {
+			n := b*batch + i
+			tickets[i] = Ticket{ID: fmt.Sprintf("AGV-%d", n), Summary: fmt.Sprintf("Ticket %d", n)}
+		}
+		a.store.Upsert(tickets...)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for a.search.Len() != batches*batch {
+		if time.Now().After(deadline) {
+			t.Fatalf("index has %d tickets, store %d", a.search.Len(), a.store.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSearchTicketsSortBy(t *testing.T) {
+	srv := youtracktest.NewServer()
+	defer srv.Close()
+	a := newTestApp(t, srv, Config{Projects: []string{"AGV", "JU"}})
+	if _, err := a.SyncTickets(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for a.search.Len() != a.store.Len() {
+		if time.Now().After(deadline) {
+			t.Fatalf("index has %d tickets, store %d", a.search.Len(), a.store.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		// The limit applies after sorting all matches
+		{query: "sort by: updated", limit: 2, want: []string{"JU-17", "AGV-952"}},
+		{query: "sort by: updated asc", limit: 3, want: []string{"AGV-951", "AGV-953", "JU-18"}},
+		{query: "project: AGV sort by: created", limit: 10, want: []string{"AGV-953", "AGV-952", "AGV-951"}},
+	}
+	for _, tt := range tests {
+		resp := a.SearchTickets(tt.query, tt.limit)
+		var got []string
+		for _, r := range resp.Results {
+			got = append(got, r.Ticket.ID)
+		}
+		if len(resp.Errors) > 0 || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchTickets(%q) = %v, errors %v; want %v", tt.query, got, resp.Errors, tt.want)
+		}
+	}
+}
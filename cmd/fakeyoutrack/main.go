@@ -0,0 +1,24 @@
+// Command fakeyoutrack runs the fake YouTrack server from internal/youtracktest
+// for offline development. Point the app's base URL at the printed address
+// and use the printed token.
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/zwoabier/youtrack-helper/internal/youtracktest"
+)
+
+func main() {
+	srv := youtracktest.NewServer()
+	defer srv.Close()
+
+	fmt.Printf("Fake YouTrack listening on %s\n", srv.URL)
+	fmt.Printf("Token: %s\n", youtracktest.Token)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+}
@@ -1,17 +1,26 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"github.com/zwoabier/youtrack-helper/internal/atomicfile"
 	"github.com/zwoabier/youtrack-helper/internal/logger"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/zalando/go-keyring"
 )
 
 type ConfigManager struct {
 	configPath string
-	config     Config
+
+	mu     sync.RWMutex // guards config and serializes writes of configPath
+	config Config
 }
 
 func NewConfigManager() *ConfigManager {
@@ -37,28 +46,30 @@ func (cm *ConfigManager) initConfigPath() error {
 }
 
 func (cm *ConfigManager) loadConfig() error {
-	if _, err := os.Stat(cm.configPath); os.IsNotExist(err) {
-		cm.config = Config{LogLevel: "debug"}
-		return nil
-	}
-
-	data, err := os.ReadFile(cm.configPath)
-	if err != nil {
-		return err
+	var cfg Config
+	_, err := atomicfile.Read(cm.configPath, func(data []byte) error {
+		cfg = Config{}
+		return json.Unmarshal(data, &cfg)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		cfg, err = Config{LogLevel: "debug"}, nil
+	} else if err != nil {
+		logger.Error("Config at %s is unreadable and has no usable backup: %v", cm.configPath, err)
+		cfg = Config{LogLevel: "debug"}
+	} else {
+		// Log loaded config (without sensitive data)
+		logger.Debug("Config loaded from %s; baseURL empty=%v, projects=%d, log_level=%s, log_to_file=%v",
+			cm.configPath, cfg.BaseURL == "", len(cfg.Projects), cfg.LogLevel, cfg.LogToFile)
 	}
-
-	if err := json.Unmarshal(data, &cm.config); err != nil {
-		return err
-	}
-
-	// Log loaded config (without sensitive data)
-	logger.Debug("Config loaded from %s; baseURL empty=%v, projects=%d, log_level=%s, log_to_file=%v",
-		cm.configPath, cm.config.BaseURL == "", len(cm.config.Projects), cm.config.LogLevel, cm.config.LogToFile)
-
-	return nil
+	cm.mu.Lock()
+	cm.config = cfg
+	cm.mu.Unlock()
+	return err
 }
 
 func (cm *ConfigManager) SaveConfig(cfg Config) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 	cm.config = cfg
 
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -66,15 +77,37 @@ func (cm *ConfigManager) SaveConfig(cfg Config) error {
 		return err
 	}
 
-	return os.WriteFile(cm.configPath, data, 0600)
+	return atomicfile.Write(cm.configPath, data, 0600)
+}
+
+// ConfigDir returns the directory holding config.json and other app state
+func (cm *ConfigManager) ConfigDir() string {
+	return filepath.Dir(cm.configPath)
+}
+
+// InstanceDir returns, creating it if needed, the directory for state that
+// belongs to one YouTrack instance (ticket cache, change log). Directories are
+// keyed by a hash of the normalized base URL so instances never share files.
+func (cm *ConfigManager) InstanceDir(baseURL string) (string, error) {
+	sum := sha256.Sum256([]byte(strings.ToLower(normalizeBaseURL(baseURL))))
+	dir := filepath.Join(cm.ConfigDir(), "instances", hex.EncodeToString(sum[:8]))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
 }
 
+// GetConfig returns a copy of the configuration. Its slices and maps are
+// shared and must not be modified.
 func (cm *ConfigManager) GetConfig() Config {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 	return cm.config
 }
 
 func (cm *ConfigManager) IsConfigured() bool {
-	return cm.config.BaseURL != "" && len(cm.config.Projects) > 0 && cm.getToken() != ""
+	cfg := cm.GetConfig()
+	return cfg.BaseURL != "" && len(cfg.Projects) > 0 && cm.getToken() != ""
 }
 
 func (cm *ConfigManager) SaveToken(token string) error {
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/zwoabier/youtrack-helper/internal/youtrack"
+)
+
+// Ticket attributes a YouTrack custom field can be mapped to with Config.FieldMapping
+const (
+	AttrType     = "type"
+	AttrPriority = "priority"
+	AttrSprints  = "sprints"
+	AttrState    = "state"
+	AttrAssignee = "assignee"
+)
+
+// defaultFieldMapping covers the stock English field names and their German
+// counterparts. Config.FieldMapping entries are applied on top of it.
+var defaultFieldMapping = map[string]string{
+	"Type":      AttrType,
+	"Typ":       AttrType,
+	"Priority":  AttrPriority,
+	"Priorität": AttrPriority,
+	"Sprints":   AttrSprints,
+	"Sprint":    AttrSprints,
+	"State":     AttrState,
+	"Assignee":  AttrAssignee,
+}
+
+// FieldValue kinds
+const (
+	FieldEnum    = "enum" // enum, state, version, build, owned and group fields
+	FieldUser    = "user"
+	FieldPeriod  = "period"
+	FieldDate    = "date"
+	FieldInteger = "integer"
+	FieldFloat   = "float"
+	FieldText    = "text"
+)
+
+// FieldValue is the parsed value of one YouTrack custom field
+type FieldValue struct {
+	Kind   string   `json:"kind"`
+	Multi  bool     `json:"multi,omitempty"`
+	Values []string `json:"values"`           // display values: names, full names, period presentations, dates, text
+	Logins []string `json:"logins,omitempty"` // user fields: logins matching Values
+	Number float64  `json:"number,omitempty"` // integer/float value, period minutes or date in unix millis
+}
+
+// First returns the first display value, or "" for an empty field
+func (v FieldValue) First() string {
+	if len(v.Values) == 0 {
+		return ""
+	}
+	return v.Values[0]
+}
+
+// fieldMapping maps YouTrack custom field names to Ticket attributes or to
+// the key the field is stored under in Ticket.CustomFields.
+type fieldMapping map[string]string
+
+// newFieldMapping merges the configured mapping over defaultFieldMapping
+func newFieldMapping(configured map[string]string) fieldMapping {
+	m := make(fieldMapping, len(defaultFieldMapping)+len(configured))
+	for name, target := range defaultFieldMapping {
+		m[name] = target
+	}
+	for name, target := range configured {
+		m[name] = target
+	}
+	return m
+}
+
+// apply stores a parsed custom field on the ticket: mapped attributes are set
+// directly, everything else goes into CustomFields under its mapped key.
+func (m fieldMapping) apply(ticket *Ticket, name string, value FieldValue) {
+	target, ok := m[name]
+	if !ok {
+		target = name
+	}
+	switch target {
+	case AttrType:
+		ticket.Type = value.First()
+	case AttrPriority:
+		ticket.Priority = value.First()
+	case AttrSprints:
+		ticket.Sprints = append(ticket.Sprints, value.Values...)
+	case AttrState:
+		ticket.State = value.First()
+	case AttrAssignee:
+		ticket.Assignee = Person{FullName: value.First()}
+		if len(value.Logins) > 0 {
+			ticket.Assignee.Login = value.Logins[0]
+		}
+	default:
+		if ticket.CustomFields == nil {
+			ticket.CustomFields = map[string]FieldValue{}
+		}
+		ticket.CustomFields[target] = value
+	}
+}
+
+// fieldValueOf converts a decoded custom field into a FieldValue. ok is false for empty fields.
+func fieldValueOf(cf youtrack.CustomField) (FieldValue, bool) {
+	if cf.Empty() {
+		return FieldValue{}, false
+	}
+	fv := FieldValue{Multi: cf.Multi}
+	switch {
+	case cf.Kind == youtrack.KindUser:
+		fv.Kind = FieldUser
+		for _, u := range cf.Users {
+			fv.Values = append(fv.Values, u.DisplayName())
+			fv.Logins = append(fv.Logins, u.Login)
+		}
+	case cf.Kind == youtrack.KindEnum:
+		fv.Kind = FieldEnum
+		for _, e := range cf.Elements {
+			fv.Values = append(fv.Values, e.Name)
+		}
+	case cf.Kind == youtrack.KindPeriod:
+		fv.Kind = FieldPeriod
+		fv.Number = float64(cf.Period.Minutes)
+		fv.Values = append(fv.Values, cf.Period.Presentation)
+	case cf.Kind == youtrack.KindDate && cf.Number != nil:
+		fv.Kind = FieldDate
+		fv.Number = *cf.Number
+		fv.Values = append(fv.Values, time.UnixMilli(int64(*cf.Number)).UTC().Format("2006-01-02"))
+	case cf.Number != nil:
+		fv.Kind = FieldFloat
+		if *cf.Number == math.Trunc(*cf.Number) {
+			fv.Kind = FieldInteger
+		}
+		fv.Number = *cf.Number
+		fv.Values = append(fv.Values, strconv.FormatFloat(*cf.Number, 'f', -1, 64))
+	case cf.Text != nil:
+		fv.Kind = FieldText
+		fv.Values = append(fv.Values, *cf.Text)
+	}
+	if len(fv.Values) == 0 && fv.Number == 0 {
+		return fv, false
+	}
+	return fv, true
+}
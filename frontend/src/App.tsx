@@ -1,13 +1,18 @@
-import React, { useEffect, useState } from "react";
+import React, { useEffect, useRef, useState } from "react";
 import { main } from 'wailsjs/go/models';
-import { GetConfig, GetTickets, SyncTickets } from 'wailsjs/go/main/App';
+import { GetConfig, SyncTickets } from 'wailsjs/go/main/App';
+import { EventsOn } from 'wailsjs/runtime';
 import { SetupWizard } from '@/components/SetupWizard';
 import { SearchInterfaceSimple } from '@/components/SearchInterfaceSimple';
 
 function App() {
   const [config, setConfig] = useState<main.Config | null>(null);
-  const [tickets, setTickets] = useState<main.Ticket[]>([]);
+  // Bumped whenever the backend's tickets changed, so the search re-runs
+  const [ticketsVersion, setTicketsVersion] = useState(0);
   const [isConfigured, setIsConfigured] = useState(false);
+  const [syncStatus, setSyncStatus] = useState("");
+  // Tickets are stored while a sync streams in; show the first page without waiting for the rest
+  const shownDuringSync = useRef(false);
 
   useEffect(() => {
     async function init() {
@@ -16,8 +21,6 @@ function App() {
 
       if (currentConfig.base_url && currentConfig.projects.length > 0) {
         setIsConfigured(true);
-        const cachedTickets = await GetTickets();
-        setTickets(cachedTickets);
         // Start background sync
         SyncTickets();
       } else {
@@ -30,16 +33,44 @@ function App() {
   // Load tickets when configuration is completed (after setup wizard)
   useEffect(() => {
     if (isConfigured && config && config.base_url && config.projects.length > 0) {
-      async function loadtickets() {
-        const cachedTickets = await GetTickets();
-        setTickets(cachedTickets);
-        // Start background sync
-        SyncTickets();
-      }
-      loadtickets();
+      setTicketsVersion((v) => v + 1);
+      // Start background sync
+      SyncTickets();
     }
   }, [isConfigured, config]);
 
+  // Live sync status from backend events; refresh the list when a sync changed it
+  useEffect(() => {
+    const offs = [
+      EventsOn("sync:started", () => {
+        shownDuringSync.current = false;
+        setSyncStatus("Syncing...");
+      }),
+      EventsOn("sync:progress", (p: { fetched: number }) => {
+        setSyncStatus(`Syncing... ${p.fetched} tickets`);
+        if (!shownDuringSync.current && p.fetched > 0) {
+          shownDuringSync.current = true;
+          setTicketsVersion((v) => v + 1);
+        }
+      }),
+      EventsOn("sync:completed", (r: { added: number; updated: number; removed: number; total: number; projects?: { project: string; error: string; status: number }[] }) => {
+        let status = `Synced ${r.total} tickets (+${r.added} ~${r.updated} -${r.removed})`;
+        const failed = (r.projects ?? []).filter((p) => p.error);
+        if (failed.length > 0) {
+          const synced = (r.projects ?? []).filter((p) => !p.error).map((p) => p.project);
+          const reasons = failed.map((p) => `${p.project} failed: ${p.status || p.error}`);
+          status = `${synced.join(", ")} synced, ${reasons.join(", ")}`;
+        }
+        setSyncStatus(status);
+        if (r.added + r.updated + r.removed > 0) {
+          setTicketsVersion((v) => v + 1);
+        }
+      }),
+      EventsOn("sync:failed", (f: { message: string }) => setSyncStatus(`Sync failed: ${f.message}`)),
+    ];
+    return () => offs.forEach((off) => off());
+  }, []);
+
   if (!config) {
     return <div>Loading...</div>;
   }
@@ -47,7 +78,7 @@ function App() {
   return (
     <div className="h-screen w-screen overflow-hidden dark">
       {isConfigured ? (
-        <SearchInterfaceSimple tickets={tickets} />
+        <SearchInterfaceSimple ticketsVersion={ticketsVersion} syncStatus={syncStatus} />
       ) : (
         <SetupWizard setConfig={setConfig} setIsConfigured={setIsConfigured} />
       )}
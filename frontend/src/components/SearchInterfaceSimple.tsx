@@ -1,85 +1,144 @@
 import React, { useEffect, useState, useRef } from "react";
 import { cn } from "@/lib/utils";
 import { Search } from 'lucide-react';
-import { main } from 'wailsjs/go/models';
-import { HideWindow, CopyToClipboard } from 'wailsjs/go/main/App';
+import { main, query, search as searchModels } from 'wailsjs/go/models';
+import { HideWindow, CopyToClipboard, OpenInBrowser, RecordUsage, ClearUsageHistory, SearchRemote, SearchTickets } from 'wailsjs/go/main/App';
 import { THEME_TAILWIND, TICKET_TYPE_TAILWIND, getPriorityBadgeClass } from '@/utils/theme';
 
+// Maximum number of results requested from the backend
+const RESULT_LIMIT = 100;
+// Typing pause before YouTrack is asked when nothing cached matches
+const REMOTE_SEARCH_DELAY_MS = 400;
+
 export interface SearchInterfaceSimpleProps {
-  tickets: main.Ticket[];
+  ticketsVersion: number;
+  syncStatus?: string;
+}
+
+// highlight wraps the matched ranges of text in <mark>. Ranges are rune
+// offsets as reported by the Go search index.
+function highlight(text: string, field: string, highlights: searchModels.Highlight[] | undefined) {
+  const ranges = (highlights ?? [])
+    .filter((h) => h.field === field)
+    .sort((a, b) => a.start - b.start);
+  if (ranges.length === 0) {
+    return text;
+  }
+  const runes = Array.from(text);
+  const parts: React.ReactNode[] = [];
+  let pos = 0;
+  ranges.forEach((h, i) => {
+    if (h.start < pos) {
+      return;
+    }
+    parts.push(runes.slice(pos, h.start).join(""));
+    parts.push(
+      <mark key={i} className="bg-transparent font-semibold underline text-inherit">
+        {runes.slice(h.start, h.end).join("")}
+      </mark>
+    );
+    pos = h.end;
+  });
+  parts.push(runes.slice(pos).join(""));
+  return parts;
+}
+
+// underlineErrors returns query with the ranges of parse errors underlined.
+// It is laid over the input with transparent text, so only the underlines show.
+function underlineErrors(text: string, errors: query.Error[]) {
+  const runes = Array.from(text);
+  const parts: React.ReactNode[] = [];
+  let pos = 0;
+  [...errors]
+    .sort((a, b) => a.start - b.start)
+    .forEach((e, i) => {
+      const start = Math.max(e.start, pos);
+      // Errors at the end of the query (e.g. a missing value) underline a space
+      const end = Math.max(e.end, start + 1);
+      parts.push(runes.slice(pos, start).join(""));
+      parts.push(
+        <span key={i} className="underline decoration-wavy decoration-red-500">
+          {runes.slice(start, end).join("") || " "}
+        </span>
+      );
+      pos = end;
+    });
+  parts.push(runes.slice(pos).join(""));
+  return parts;
 }
 
-export function SearchInterfaceSimple({ tickets }: SearchInterfaceSimpleProps) {
+export function SearchInterfaceSimple({ ticketsVersion, syncStatus }: SearchInterfaceSimpleProps) {
   const [search, setSearch] = useState("");
   const [selectedIndex, setSelectedIndex] = useState(0);
-  const [filteredTickets, setFilteredTickets] = useState<main.Ticket[]>([]);
+  const [results, setResults] = useState<main.SearchResult[]>([]);
+  const [queryErrors, setQueryErrors] = useState<query.Error[]>([]);
+  const [remoteStatus, setRemoteStatus] = useState("");
+  // Bumped when the usage history is cleared, so the ranking is refreshed
+  const [usageVersion, setUsageVersion] = useState(0);
   
   const inputRef = useRef<HTMLInputElement>(null);
   const selectedItemRef = useRef<HTMLDivElement>(null);
   const resultsContainerRef = useRef<HTMLDivElement>(null);
   const lastSearchRef = useRef("");
+  const requestRef = useRef(0);
+  // Result the user moved to with the arrow keys. Leaving it without copying
+  // or opening it is recorded as a selection.
+  const pickedRef = useRef<string | null>(null);
 
-  // Effect 1: Filter and rank tickets based on search query
-  useEffect(() => {
-    if (search.trim() === "") {
-      // No search: show all tickets
-      setFilteredTickets(tickets);
-    } else {
-      // Calculate relevance scores for each ticket
-      const searchLower = search.toLowerCase();
-      const scored = tickets.map((ticket) => {
-        let score = 0;
-
-        // ID exact match: 1000 points
-        if (ticket.id.toLowerCase() === searchLower) {
-          score += 1000;
-        }
-        // ID starts with query: 800 points
-        else if (ticket.id.toLowerCase().startsWith(searchLower)) {
-          score += 800;
-        }
-        // ID contains query: 600 points
-        else if (ticket.id.toLowerCase().includes(searchLower)) {
-          score += 600;
-        }
-
-        // Summary contains query: 400 points
-        if (ticket.summary.toLowerCase().includes(searchLower)) {
-          score += 400;
-        }
-
-        // Type contains query: 200 points
-        if (ticket.type && ticket.type.toLowerCase().includes(searchLower)) {
-          score += 200;
-        }
-
-        // Priority contains query: 100 points
-        if (ticket.priority && ticket.priority.toLowerCase().includes(searchLower)) {
-          score += 100;
-        }
+  const recordPicked = () => {
+    if (pickedRef.current) {
+      RecordUsage(pickedRef.current, "select");
+      pickedRef.current = null;
+    }
+  };
 
-        // Sprints contain query: 100 points
-        if (ticket.sprints && ticket.sprints.some(s => s.toLowerCase().includes(searchLower))) {
-          score += 100;
+  // Effect 1: Ask the backend to filter and rank tickets for the query.
+  // Responses to older queries are dropped if a newer one was sent meanwhile.
+  // When nothing cached matches, YouTrack itself is searched once typing
+  // pauses; the backend cancels the previous remote search on every call.
+  useEffect(() => {
+    const request = ++requestRef.current;
+    let remoteTimer: ReturnType<typeof setTimeout> | undefined;
+    setRemoteStatus("");
+    SearchTickets(search, RESULT_LIMIT).then((response) => {
+      if (request !== requestRef.current) {
+        return;
+      }
+      const found = response.results ?? [];
+      setResults(found);
+      setQueryErrors(response.errors ?? []);
+      if (found.length > 0 || search.trim() === "") {
+        return;
+      }
+      remoteTimer = setTimeout(() => {
+        if (request !== requestRef.current) {
+          return;
         }
-
-        return { ticket, score };
-      });
-
-      // Filter out zero-score results and sort by score descending
-      const filtered = scored
-        .filter(item => item.score > 0)
-        .sort((a, b) => b.score - a.score)
-        .map(item => item.ticket);
-
-      setFilteredTickets(filtered);
-    }
-  }, [search, tickets]);
+        setRemoteStatus("Searching YouTrack...");
+        SearchRemote(search)
+          .then((remote) => {
+            if (request === requestRef.current) {
+              setResults(remote.results ?? []);
+              setRemoteStatus("");
+            }
+          })
+          .catch((err) => {
+            // A superseded search is cancelled on purpose
+            if (request === requestRef.current) {
+              setRemoteStatus(String(err));
+            }
+          });
+      }, REMOTE_SEARCH_DELAY_MS);
+    });
+    return () => clearTimeout(remoteTimer);
+  }, [search, ticketsVersion, usageVersion]);
 
   // Effect 2: Reset selection and scroll to top when search query changes
   useEffect(() => {
     if (search !== lastSearchRef.current) {
       setSelectedIndex(0);
+      // Refining the query isn't a choice of the highlighted result
+      pickedRef.current = null;
       // Scroll container to top
       if (resultsContainerRef.current) {
         resultsContainerRef.current.scrollTop = 0;
@@ -93,30 +152,40 @@ export function SearchInterfaceSimple({ tickets }: SearchInterfaceSimpleProps) {
     if (selectedItemRef.current) {
       selectedItemRef.current.scrollIntoView({ behavior: "smooth", block: "nearest" });
     }
-  }, [selectedIndex, filteredTickets]);
+  }, [selectedIndex, results]);
 
   // Keyboard navigation handler
   const handleKeyDown = (e: KeyboardEvent) => {
     if (e.key === "Escape") {
+      recordPicked();
       if (search === "") {
         HideWindow();
       } else {
         setSearch("");
       }
-    } else if (e.key === "ArrowDown") {
-      e.preventDefault();
-      setSelectedIndex((prev) =>
-        prev < filteredTickets.length - 1 ? prev + 1 : prev
-      );
-    } else if (e.key === "ArrowUp") {
+    } else if (e.key === "ArrowDown" || e.key === "ArrowUp") {
       e.preventDefault();
-      setSelectedIndex((prev) => (prev > 0 ? prev - 1 : prev));
+      if (results.length === 0) {
+        return;
+      }
+      const next = e.key === "ArrowDown"
+        ? Math.min(selectedIndex + 1, results.length - 1)
+        : Math.max(selectedIndex - 1, 0);
+      setSelectedIndex(next);
+      pickedRef.current = results[next]?.ticket.id ?? null;
     } else if (e.key === "Enter") {
       e.preventDefault();
-      if (filteredTickets[selectedIndex]) {
-        const ticket = filteredTickets[selectedIndex];
-        const markdownLink = `[${ticket.id}](${ticket.url})`;
-        CopyToClipboard(markdownLink);
+      if (results[selectedIndex]) {
+        const ticket = results[selectedIndex].ticket;
+        pickedRef.current = null;
+        if (e.shiftKey) {
+          OpenInBrowser(ticket.url);
+          RecordUsage(ticket.id, "open");
+        } else {
+          const markdownLink = `[${ticket.id}](${ticket.url})`;
+          CopyToClipboard(markdownLink);
+          RecordUsage(ticket.id, "copy");
+        }
         HideWindow();
       }
     }
@@ -125,41 +194,66 @@ export function SearchInterfaceSimple({ tickets }: SearchInterfaceSimpleProps) {
   useEffect(() => {
     window.addEventListener("keydown", handleKeyDown);
     return () => window.removeEventListener("keydown", handleKeyDown);
-  }, [search, filteredTickets, selectedIndex]);
+  }, [search, results, selectedIndex]);
+
+  // Clicking away hides the window, which also leaves the highlighted result
+  useEffect(() => {
+    window.addEventListener("blur", recordPicked);
+    return () => window.removeEventListener("blur", recordPicked);
+  }, []);
 
   const handleTicketSelect = async (ticket: main.Ticket) => {
+    pickedRef.current = null;
     const markdownLink = `[${ticket.id}](${ticket.url})`;
     await CopyToClipboard(markdownLink);
+    RecordUsage(ticket.id, "copy");
     HideWindow();
   };
 
+  const handleClearHistory = async () => {
+    await ClearUsageHistory();
+    setUsageVersion((v) => v + 1);
+  };
+
   return (
     <div className={`h-screen w-screen ${THEME_TAILWIND.bgBase} flex flex-col overflow-hidden`}>
       {/* Search Input */}
       <div className={`p-4 ${THEME_TAILWIND.borderBottom}`}>
         <div className={`flex items-center gap-2 ${THEME_TAILWIND.bgSurface} rounded-lg px-3 py-2 border border-[hsl(var(--color-border))]`}>
           <Search size={18} className={THEME_TAILWIND.textSecondary} />
-          <input
-            ref={inputRef}
-            type="text"
-            value={search}
-            onChange={(e) => setSearch(e.target.value)}
-            placeholder="Search tickets..."
-            className={`flex-1 ${THEME_TAILWIND.bgSurface} outline-none ${THEME_TAILWIND.textPrimary} placeholder-[hsl(var(--color-text-muted))]`}
-            autoFocus
-          />
+          <div className="relative flex-1">
+            <input
+              ref={inputRef}
+              type="text"
+              value={search}
+              onChange={(e) => setSearch(e.target.value)}
+              placeholder="Search tickets or filter, e.g. Type: Bug #Unresolved for: me"
+              className={`w-full ${THEME_TAILWIND.bgSurface} outline-none ${THEME_TAILWIND.textPrimary} placeholder-[hsl(var(--color-text-muted))]`}
+              autoFocus
+            />
+            {queryErrors.length > 0 && (
+              <div className="absolute inset-0 pointer-events-none whitespace-pre overflow-hidden text-transparent">
+                {underlineErrors(search, queryErrors)}
+              </div>
+            )}
+          </div>
         </div>
+        {queryErrors.length > 0 && (
+          <div className="mt-2 text-xs text-red-500">
+            {queryErrors.map((e) => e.message).join(" · ")}
+          </div>
+        )}
       </div>
 
       {/* Results List */}
       <div ref={resultsContainerRef} className="flex-1 overflow-y-auto">
-        {filteredTickets.length === 0 ? (
+        {results.length === 0 ? (
           <div className={`flex items-center justify-center h-full ${THEME_TAILWIND.textSecondary} p-4`}>
-            No tickets found
+            {remoteStatus || "No tickets found"}
           </div>
         ) : (
           <div>
-            {filteredTickets.map((ticket, index) => (
+            {results.map(({ ticket, highlights, cached }, index) => (
               <div
                 key={ticket.id}
                 ref={index === selectedIndex ? selectedItemRef : null}
@@ -174,8 +268,15 @@ export function SearchInterfaceSimple({ tickets }: SearchInterfaceSimpleProps) {
                 {/* Header: ID, Type Badge, Priority Badge */}
                 <div className="flex items-center gap-3 mb-2">
                   <span className={`font-bold min-w-fit ${THEME_TAILWIND.accent}`}>
-                    {ticket.id}
+                    {highlight(ticket.id, "id", highlights)}
                   </span>
+
+                  {/* Found on the server, not in the local cache */}
+                  {!cached && (
+                    <span className={`inline-block px-2 py-1 rounded text-xs border border-[hsl(var(--color-border))] ${THEME_TAILWIND.textSecondary}`}>
+                      not cached
+                    </span>
+                  )}
                   
                   {/* Type Badge - Color-coded by type */}
                   <span className={cn(
@@ -199,7 +300,7 @@ export function SearchInterfaceSimple({ tickets }: SearchInterfaceSimpleProps) {
                 {/* Title */}
                 <div className="mb-2">
                   <p className={`${THEME_TAILWIND.textPrimary} text-sm leading-5`}>
-                    {ticket.summary}
+                    {highlight(ticket.summary, "summary", highlights)}
                   </p>
                 </div>
 
@@ -224,7 +325,13 @@ export function SearchInterfaceSimple({ tickets }: SearchInterfaceSimpleProps) {
 
       {/* Keyboard Hints Footer */}
       <div className={`p-3 border-t border-[hsl(var(--color-border))] text-xs ${THEME_TAILWIND.textSecondary} space-y-1`}>
-        <div>Enter - Copy URL | Shift+Enter - Open in Browser | Esc - Close</div>
+        <div className="flex">
+          <span className="flex-1">Enter - Copy URL | Shift+Enter - Open in Browser | Esc - Close</span>
+          <button type="button" onClick={handleClearHistory} className="hover:underline">
+            Clear history
+          </button>
+        </div>
+        {syncStatus && <div>{syncStatus}</div>}
       </div>
     </div>
   );
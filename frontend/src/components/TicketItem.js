@@ -1,7 +0,0 @@
-import { jsx as _jsx, jsxs as _jsxs } from "react/jsx-runtime";
-import { forwardRef } from 'react';
-import clsx from 'clsx';
-import { PRIORITY_TAILWIND, THEME_TAILWIND } from '@/utils/theme';
-export const TicketItem = forwardRef(function TicketItem({ ticket, isSelected, onSelect }, ref) {
-    return (_jsxs("div", { ref: ref, onClick: onSelect, className: clsx('px-4 py-3 cursor-pointer transition-colors border-l-3 border-l-transparent', isSelected ? `${THEME_TAILWIND.bgHover} border-l-[hsl(var(--color-accent-bright))]` : `hover:${THEME_TAILWIND.bgHover}`), children: [_jsxs("div", { className: "flex items-center gap-3 mb-2", children: [_jsx("span", { className: clsx('font-bold min-w-fit', THEME_TAILWIND.accent), children: ticket.id }), _jsx("span", { className: clsx('flex-1 truncate', THEME_TAILWIND.textPrimary), children: ticket.summary }), _jsx("span", { className: clsx('font-medium text-sm', PRIORITY_TAILWIND[ticket.priority] || PRIORITY_TAILWIND['']), children: ticket.priority || '-' })] }), _jsxs("div", { className: clsx('flex items-center gap-3 text-sm', THEME_TAILWIND.textSecondary), children: [_jsx("span", { className: "min-w-fit", children: ticket.type || 'Unknown' }), _jsx("div", { className: "flex-1" }), ticket.sprints && ticket.sprints.length > 0 && (_jsx("div", { className: "flex gap-2", children: ticket.sprints.map((sprint) => (_jsx("span", { className: clsx('px-2 py-1 border rounded-full text-xs', THEME_TAILWIND.border, THEME_TAILWIND.textSecondary), children: sprint }, sprint))) }))] })] }));
-});
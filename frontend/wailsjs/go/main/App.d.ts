@@ -2,28 +2,54 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function ClearUsageHistory():Promise<void>;
+
 export function CopyToClipboard(arg1:string):Promise<void>;
 
 export function FetchProjects(arg1:string,arg2:string):Promise<Array<main.Project>>;
 
 export function FrontendLog(arg1:string,arg2:Record<string, any>):Promise<void>;
 
+export function FullResync():Promise<Array<main.Ticket>>;
+
+export function GetAPIStats():Promise<main.APIStats>;
+
 export function GetConfig():Promise<main.Config>;
 
 export function GetCurrentUser(arg1:string,arg2:string):Promise<main.User>;
 
+export function GetRecentChanges(arg1:number):Promise<Array<main.TicketChange>>;
+
+export function GetSyncStatus():Promise<main.SyncStatus>;
+
 export function GetTickets():Promise<Array<main.Ticket>>;
 
+export function GetTicketsBy(arg1:string,arg2:string):Promise<Array<main.Ticket>>;
+
+export function GetTicketsPage(arg1:string,arg2:number):Promise<main.TicketPage>;
+
 export function GetYouTrackToken():Promise<string>;
 
 export function HideWindow():Promise<void>;
 
 export function OpenInBrowser(arg1:string):Promise<void>;
 
+export function PauseSync():Promise<void>;
+
+export function RecordUsage(arg1:string,arg2:string):Promise<void>;
+
+export function ResumeSync():Promise<void>;
+
 export function SaveConfig(arg1:main.Config):Promise<void>;
 
 export function SaveYouTrackToken(arg1:string):Promise<void>;
 
+export function SearchRemote(arg1:string):Promise<main.SearchResponse>;
+
+export function SearchTickets(arg1:string,arg2:number):Promise<main.SearchResponse>;
+
 export function SyncTickets():Promise<Array<main.Ticket>>;
 
+export function TriggerSync():Promise<void>;
+
 export function ValidateYouTrackToken(arg1:string,arg2:string):Promise<boolean>;
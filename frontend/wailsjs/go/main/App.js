@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ClearUsageHistory() {
+  return window['go']['main']['App']['ClearUsageHistory']();
+}
+
 export function CopyToClipboard(arg1) {
   return window['go']['main']['App']['CopyToClipboard'](arg1);
 }
@@ -14,6 +18,14 @@ export function FrontendLog(arg1, arg2) {
   return window['go']['main']['App']['FrontendLog'](arg1, arg2);
 }
 
+export function FullResync() {
+  return window['go']['main']['App']['FullResync']();
+}
+
+export function GetAPIStats() {
+  return window['go']['main']['App']['GetAPIStats']();
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
@@ -22,10 +34,26 @@ export function GetCurrentUser(arg1, arg2) {
   return window['go']['main']['App']['GetCurrentUser'](arg1, arg2);
 }
 
+export function GetRecentChanges(arg1) {
+  return window['go']['main']['App']['GetRecentChanges'](arg1);
+}
+
+export function GetSyncStatus() {
+  return window['go']['main']['App']['GetSyncStatus']();
+}
+
 export function GetTickets() {
   return window['go']['main']['App']['GetTickets']();
 }
 
+export function GetTicketsBy(arg1, arg2) {
+  return window['go']['main']['App']['GetTicketsBy'](arg1, arg2);
+}
+
+export function GetTicketsPage(arg1, arg2) {
+  return window['go']['main']['App']['GetTicketsPage'](arg1, arg2);
+}
+
 export function GetYouTrackToken() {
   return window['go']['main']['App']['GetYouTrackToken']();
 }
@@ -38,6 +66,18 @@ export function OpenInBrowser(arg1) {
   return window['go']['main']['App']['OpenInBrowser'](arg1);
 }
 
+export function PauseSync() {
+  return window['go']['main']['App']['PauseSync']();
+}
+
+export function RecordUsage(arg1, arg2) {
+  return window['go']['main']['App']['RecordUsage'](arg1, arg2);
+}
+
+export function ResumeSync() {
+  return window['go']['main']['App']['ResumeSync']();
+}
+
 export function SaveConfig(arg1) {
   return window['go']['main']['App']['SaveConfig'](arg1);
 }
@@ -46,10 +86,22 @@ export function SaveYouTrackToken(arg1) {
   return window['go']['main']['App']['SaveYouTrackToken'](arg1);
 }
 
+export function SearchRemote(arg1) {
+  return window['go']['main']['App']['SearchRemote'](arg1);
+}
+
+export function SearchTickets(arg1, arg2) {
+  return window['go']['main']['App']['SearchTickets'](arg1, arg2);
+}
+
 export function SyncTickets() {
   return window['go']['main']['App']['SyncTickets']();
 }
 
+export function TriggerSync() {
+  return window['go']['main']['App']['TriggerSync']();
+}
+
 export function ValidateYouTrackToken(arg1, arg2) {
   return window['go']['main']['App']['ValidateYouTrackToken'](arg1, arg2);
 }
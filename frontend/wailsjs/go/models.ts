@@ -1,5 +1,17 @@
 export namespace main {
 	
+	export class APIStats {
+	    delayed_requests: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new APIStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.delayed_requests = source["delayed_requests"];
+	    }
+	}
 	export class Config {
 	    base_url: string;
 	    projects: string[];
@@ -7,6 +19,27 @@ export namespace main {
 	    last_sync_time: number;
 	    log_level: string;
 	    log_to_file: boolean;
+	    last_full_sync_time: number;
+	    full_sync_interval_hours: number;
+	    sync_interval_minutes: number;
+	    per_project_sync: boolean;
+	    cache_backend: string;
+	    rate_limit_per_second: number;
+	    rate_limit_burst: number;
+	    max_concurrent_requests: number;
+	    http_timeout_seconds: number;
+	    dial_timeout_seconds: number;
+	    tls_timeout_seconds: number;
+	    proxy: string;
+	    ca_bundle: string;
+	    client_cert: string;
+	    client_key: string;
+	    disable_gzip: boolean;
+	    disable_umlaut_folding: boolean;
+	    disable_diacritic_stripping: boolean;
+	    disable_stemming: boolean;
+	    disable_compound_splitting: boolean;
+	    field_mapping: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -20,6 +53,77 @@ export namespace main {
 	        this.last_sync_time = source["last_sync_time"];
 	        this.log_level = source["log_level"];
 	        this.log_to_file = source["log_to_file"];
+	        this.last_full_sync_time = source["last_full_sync_time"];
+	        this.full_sync_interval_hours = source["full_sync_interval_hours"];
+	        this.sync_interval_minutes = source["sync_interval_minutes"];
+	        this.per_project_sync = source["per_project_sync"];
+	        this.cache_backend = source["cache_backend"];
+	        this.rate_limit_per_second = source["rate_limit_per_second"];
+	        this.rate_limit_burst = source["rate_limit_burst"];
+	        this.max_concurrent_requests = source["max_concurrent_requests"];
+	        this.http_timeout_seconds = source["http_timeout_seconds"];
+	        this.dial_timeout_seconds = source["dial_timeout_seconds"];
+	        this.tls_timeout_seconds = source["tls_timeout_seconds"];
+	        this.proxy = source["proxy"];
+	        this.ca_bundle = source["ca_bundle"];
+	        this.client_cert = source["client_cert"];
+	        this.client_key = source["client_key"];
+	        this.disable_gzip = source["disable_gzip"];
+	        this.disable_umlaut_folding = source["disable_umlaut_folding"];
+	        this.disable_diacritic_stripping = source["disable_diacritic_stripping"];
+	        this.disable_stemming = source["disable_stemming"];
+	        this.disable_compound_splitting = source["disable_compound_splitting"];
+	        this.field_mapping = source["field_mapping"];
+	    }
+	}
+	export class FieldChange {
+	    field: string;
+	    old: string;
+	    new: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class FieldValue {
+	    kind: string;
+	    multi?: boolean;
+	    values: string[];
+	    logins?: string[];
+	    number?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldValue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.multi = source["multi"];
+	        this.values = source["values"];
+	        this.logins = source["logins"];
+	        this.number = source["number"];
+	    }
+	}
+	export class Person {
+	    login: string;
+	    full_name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Person(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.login = source["login"];
+	        this.full_name = source["full_name"];
 	    }
 	}
 	export class Project {
@@ -40,6 +144,96 @@ export namespace main {
 	        this.archived = source["archived"];
 	    }
 	}
+	export class SearchResponse {
+	    results: SearchResult[];
+	    errors: query.Error[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.results = this.convertValues(source["results"], SearchResult);
+	        this.errors = this.convertValues(source["errors"], query.Error);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchResult {
+	    ticket: Ticket;
+	    score: number;
+	    highlights: search.Highlight[];
+	    cached: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ticket = this.convertValues(source["ticket"], Ticket);
+	        this.score = source["score"];
+	        this.highlights = this.convertValues(source["highlights"], search.Highlight);
+	        this.cached = source["cached"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncStatus {
+	    paused: boolean;
+	    running: boolean;
+	    offline: boolean;
+	    failures: number;
+	    last_error: string;
+	    next_sync: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.paused = source["paused"];
+	        this.running = source["running"];
+	        this.offline = source["offline"];
+	        this.failures = source["failures"];
+	        this.last_error = source["last_error"];
+	        this.next_sync = source["next_sync"];
+	    }
+	}
 	export class Ticket {
 	    id: string;
 	    summary: string;
@@ -47,6 +241,14 @@ export namespace main {
 	    priority: string;
 	    sprints: string[];
 	    url: string;
+	    state: string;
+	    resolved: boolean;
+	    assignee: Person;
+	    reporter: Person;
+	    created: number;
+	    updated: number;
+	    resolved_at: number;
+	    custom_fields?: Record<string, FieldValue>;
 	
 	    static createFrom(source: any = {}) {
 	        return new Ticket(source);
@@ -60,10 +262,107 @@ export namespace main {
 	        this.priority = source["priority"];
 	        this.sprints = source["sprints"];
 	        this.url = source["url"];
+	        this.state = source["state"];
+	        this.resolved = source["resolved"];
+	        this.assignee = this.convertValues(source["assignee"], Person);
+	        this.reporter = this.convertValues(source["reporter"], Person);
+	        this.created = source["created"];
+	        this.updated = source["updated"];
+	        this.resolved_at = source["resolved_at"];
+	        this.custom_fields = this.convertValues(source["custom_fields"], FieldValue, true);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TicketChange {
+	    time: number;
+	    ticket_id: string;
+	    kind: string;
+	    summary: string;
+	    fields?: FieldChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TicketChange(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.ticket_id = source["ticket_id"];
+	        this.kind = source["kind"];
+	        this.summary = source["summary"];
+	        this.fields = this.convertValues(source["fields"], FieldChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TicketPage {
+	    tickets: Ticket[];
+	    next: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TicketPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tickets = this.convertValues(source["tickets"], Ticket);
+	        this.next = source["next"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class User {
 	    id: string;
+	    login: string;
 	    name: string;
 	    email: string;
 	    $type: string;
@@ -75,6 +374,7 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
+	        this.login = source["login"];
 	        this.name = source["name"];
 	        this.email = source["email"];
 	        this.$type = source["$type"];
@@ -83,3 +383,46 @@ export namespace main {
 
 }
 
+export namespace query {
+	
+	export class Error {
+	    start: number;
+	    end: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Error(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.message = source["message"];
+	    }
+	}
+
+}
+
+export namespace search {
+	
+	export class Highlight {
+	    field: string;
+	    index: number;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Highlight(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.index = source["index"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+
+}
@@ -0,0 +1,137 @@
+// Package atomicfile writes files so that a crash mid-write never leaves a
+// truncated file behind, and reads them back with automatic recovery from
+// the previous generation.
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+// BackupSuffix is appended to a file's path to name its previous generation
+const BackupSuffix = ".bak"
+
+// Write replaces the file at path with data. The data is written to a
+// temporary file in the same directory and fsynced; the current file, if any,
+// becomes path+BackupSuffix, and the temporary file is renamed into place.
+func Write(path string, data []byte, perm os.FileMode) error {
+	tmp, err := writeTemp(path, data, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if err := os.Rename(path, path+BackupSuffix); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("keeping backup of %s: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// Read returns the contents of the file at path. If the file is missing,
+// empty or rejected by validate (which may be nil), Read falls back to the
+// backup generation and restores it in place. When neither file exists the
+// returned error wraps fs.ErrNotExist.
+func Read(path string, validate func([]byte) error) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err = check(data, validate); err == nil {
+			return data, nil
+		}
+	}
+	primaryErr := err
+
+	backup, err := os.ReadFile(path + BackupSuffix)
+	if err != nil {
+		// Nothing to recover from; report the original problem
+		return nil, primaryErr
+	}
+	if err := check(backup, validate); err != nil {
+		return nil, fmt.Errorf("%s and its backup are unreadable: %v; %v", path, primaryErr, err)
+	}
+
+	if errors.Is(primaryErr, fs.ErrNotExist) {
+		logger.Warn("%s is missing; restoring it from backup", path)
+	} else {
+		logger.Warn("%s is corrupt (%v); restoring it from backup", path, primaryErr)
+	}
+	if err := restore(path, backup); err != nil {
+		logger.Warn("restoring %s from backup: %v", path, err)
+	}
+	return backup, nil
+}
+
+// check rejects empty data and anything validate refuses
+func check(data []byte, validate func([]byte) error) error {
+	if len(data) == 0 {
+		return errors.New("file is empty")
+	}
+	if validate != nil {
+		return validate(data)
+	}
+	return nil
+}
+
+// restore writes data to path without rotating the (corrupt) current file into the backup slot
+func restore(path string, data []byte) error {
+	tmp, err := writeTemp(path, data, 0600)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// writeTemp writes and fsyncs data to a new temporary file next to path and returns its name
+func writeTemp(path string, data []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temp file for %s: %w", path, err)
+	}
+	tmp := f.Name()
+	fail := func(err error) (string, error) {
+		f.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("writing temp file for %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := f.Chmod(perm); err != nil {
+		return fail(err)
+	}
+	if err := f.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("writing temp file for %s: %w", path, err)
+	}
+	return tmp, nil
+}
+
+// syncDir makes a rename durable. It is best effort: some platforms
+// (Windows) can't fsync directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	d.Close()
+}
@@ -0,0 +1,132 @@
+package atomicfile
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validJSON rejects anything that isn't a JSON document, like a truncated write
+func validJSON(data []byte) error {
+	var v any
+	return json.Unmarshal(data, &v)
+}
+
+func TestRead(t *testing.T) {
+	const good, older = `{"v":2}`, `{"v":1}`
+	tests := []struct {
+		name        string
+		primary     *string // nil: file missing
+		backup      *string
+		want        string
+		wantErr     string
+		notExist    bool
+		wantPrimary string // contents of the primary file afterwards; "" skips the check
+	}{
+		{name: "primary is good", primary: ptr(good), backup: ptr(older), want: good, wantPrimary: good},
+		{name: "truncated primary restores backup", primary: ptr(`{"v":`), backup: ptr(older), want: older, wantPrimary: older},
+		{name: "empty primary restores backup", primary: ptr(""), backup: ptr(older), want: older, wantPrimary: older},
+		{name: "missing primary restores backup", backup: ptr(older), want: older, wantPrimary: older},
+		{name: "primary without backup", primary: ptr(good), want: good, wantPrimary: good},
+		{name: "both missing", notExist: true},
+		{name: "truncated primary without backup", primary: ptr(`{"v":`), wantErr: "unexpected end of JSON input"},
+		{name: "both corrupt", primary: ptr(`{"v":`), backup: ptr(`[`), wantErr: "its backup are unreadable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			if tt.primary != nil {
+				writeFile(t, path, *tt.primary)
+			}
+			if tt.backup != nil {
+				writeFile(t, path+BackupSuffix, *tt.backup)
+			}
+
+			data, err := Read(path, validJSON)
+			switch {
+			case tt.notExist:
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("Read error = %v, want fs.ErrNotExist", err)
+				}
+				return
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Read error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Read: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Read = %q, want %q", data, tt.want)
+			}
+			if tt.wantPrimary != "" {
+				if got, _ := os.ReadFile(path); string(got) != tt.wantPrimary {
+					t.Errorf("primary file = %q, want %q", got, tt.wantPrimary)
+				}
+			}
+			assertNoTemp(t, filepath.Dir(path))
+		})
+	}
+}
+
+func TestWriteKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	for _, v := range []string{`{"v":1}`, `{"v":2}`} {
+		if err := Write(path, []byte(v), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := os.ReadFile(path); string(got) != `{"v":2}` {
+		t.Errorf("file = %q", got)
+	}
+	if got, _ := os.ReadFile(path + BackupSuffix); string(got) != `{"v":1}` {
+		t.Errorf("backup = %q", got)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, %v", info.Mode(), err)
+	}
+	assertNoTemp(t, filepath.Dir(path))
+}
+
+func TestWriteRemovesTempOnRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	writeFile(t, path, `{"v":1}`)
+	// A non-empty directory in the backup slot makes rotating the current file fail
+	if err := os.MkdirAll(filepath.Join(path+BackupSuffix, "occupied"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Write(path, []byte(`{"v":2}`), 0600); err == nil {
+		t.Fatal("Write succeeded although the backup couldn't be kept")
+	}
+	if got, _ := os.ReadFile(path); string(got) != `{"v":1}` {
+		t.Errorf("file = %q, want it unchanged", got)
+	}
+	assertNoTemp(t, dir)
+}
+
+func ptr(s string) *string { return &s }
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertNoTemp(t *testing.T, dir string) {
+	t.Helper()
+	tmps, err := filepath.Glob(filepath.Join(dir, "*.tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmps) > 0 {
+		t.Errorf("temp files left behind: %v", tmps)
+	}
+}
@@ -82,10 +82,12 @@ func write(level int, format string, args ...interface{}) {
 
 	fmt.Print(line)
 
+	mu.Lock()
+	defer mu.Unlock()
+	// SetLogToFile may run concurrently, so logToFile is read under mu too
 	if !logToFile {
 		return
 	}
-	mu.Lock()
 	if file == nil && filePath != "" {
 		f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
 		if err == nil {
@@ -95,7 +97,6 @@ func write(level int, format string, args ...interface{}) {
 	if file != nil {
 		file.WriteString(line)
 	}
-	mu.Unlock()
 }
 
 // Debug logs at debug level.
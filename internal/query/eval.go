@@ -0,0 +1,291 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// expr is a node of the parsed filter
+type expr interface {
+	match(r Record, env Env) bool
+}
+
+type andExpr []expr
+
+func (a andExpr) match(r Record, env Env) bool {
+	for _, e := range a {
+		if !e.match(r, env) {
+			return false
+		}
+	}
+	return true
+}
+
+// and joins terms, dropping the wrapper for zero or one term
+func and(terms []expr) expr {
+	switch len(terms) {
+	case 0:
+		return nil
+	case 1:
+		return terms[0]
+	}
+	return andExpr(terms)
+}
+
+type orExpr []expr
+
+func (o orExpr) match(r Record, env Env) bool {
+	for _, e := range o {
+		if e.match(r, env) {
+			return true
+		}
+	}
+	return false
+}
+
+type notExpr struct{ e expr }
+
+func (n notExpr) match(r Record, env Env) bool {
+	return !n.e.match(r, env)
+}
+
+// tagExpr is #tag: #Resolved and #Unresolved check the resolution, any other
+// tag is compared with the record's TagValues
+type tagExpr struct{ tag string }
+
+func (t tagExpr) match(r Record, env Env) bool {
+	switch t.tag {
+	case "resolved":
+		return r.Resolved()
+	case "unresolved":
+		return !r.Resolved()
+	}
+	for _, v := range r.TagValues() {
+		if strings.EqualFold(v, t.tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// textExpr matches records whose summary contains word; used for -word
+type textExpr struct{ word string }
+
+func (t textExpr) match(r Record, env Env) bool {
+	for _, v := range r.Values("summary") {
+		if strings.Contains(strings.ToLower(v), t.word) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldExpr is "field: a, b, -c": any of the included values and none of the
+// excluded ones must match. Without included values only exclusion applies.
+type fieldExpr struct {
+	field            string
+	kind             Kind
+	include, exclude []valueMatcher
+}
+
+func (f fieldExpr) match(r Record, env Env) bool {
+	for _, m := range f.exclude {
+		if f.matchValue(m, r, env) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, m := range f.include {
+		if f.matchValue(m, r, env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fieldExpr) matchValue(m valueMatcher, r Record, env Env) bool {
+	switch f.kind {
+	case KindDate, KindNumber:
+		n, ok := r.Number(f.field)
+		return ok && n >= m.lo && n <= m.hi
+	}
+	want := m.text
+	if f.kind == KindUser && want == "me" {
+		// Without a known login "me" matches nobody, not unassigned records
+		if env.Me == nil {
+			return false
+		}
+		if want = strings.ToLower(env.Me()); want == "" {
+			return false
+		}
+	}
+	for _, v := range r.Values(f.field) {
+		v = strings.ToLower(v)
+		if v == want || f.kind == KindText && strings.Contains(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortKey is one field of a sort by: clause. Like in YouTrack the order is
+// descending unless asc is given; enum values sort alphabetically since
+// their configured order isn't known here.
+type sortKey struct {
+	field string
+	kind  Kind
+	desc  bool
+}
+
+// compare orders a and b by the key; records without a value come last in
+// either direction
+func (k sortKey) compare(a, b Record) int {
+	var c int
+	switch k.kind {
+	case KindDate, KindNumber:
+		x, okA := a.Number(k.field)
+		y, okB := b.Number(k.field)
+		if okA != okB {
+			return emptyLast(okA)
+		}
+		c = cmp.Compare(x, y)
+	default:
+		x, y := firstValue(a, k.field), firstValue(b, k.field)
+		if (x != "") != (y != "") {
+			return emptyLast(x != "")
+		}
+		c = strings.Compare(x, y)
+	}
+	if k.desc {
+		return -c
+	}
+	return c
+}
+
+// emptyLast orders the record that has a value first
+func emptyLast(aHasValue bool) int {
+	if aHasValue {
+		return -1
+	}
+	return 1
+}
+
+// firstValue returns the lower-cased first non-empty value of field
+func firstValue(r Record, field string) string {
+	for _, v := range r.Values(field) {
+		if v != "" {
+			return strings.ToLower(v)
+		}
+	}
+	return ""
+}
+
+// valueMatcher is one value of a field term: lower-cased text, or an
+// inclusive lo..hi range for dates and numbers
+type valueMatcher struct {
+	text   string
+	lo, hi float64
+}
+
+// Open range bound
+const unbounded = "*"
+
+// valueMatcherFor returns the matcher for a single value of a field of kind
+func valueMatcherFor(kind Kind, value string) (valueMatcher, error) {
+	switch kind {
+	case KindDate:
+		lo, hi, err := parseDate(value)
+		return valueMatcher{lo: lo, hi: hi}, err
+	case KindNumber:
+		n, err := parseNumber(value)
+		return valueMatcher{lo: n, hi: n}, err
+	}
+	return valueMatcher{text: strings.ToLower(value)}, nil
+}
+
+// rangeMatcher returns the matcher for from .. to. A date range includes the
+// whole last day; * leaves a side open.
+func rangeMatcher(kind Kind, from, to string) (valueMatcher, error) {
+	if kind != KindDate && kind != KindNumber {
+		return valueMatcher{}, fmt.Errorf("ranges only work on date and number fields")
+	}
+	m := valueMatcher{lo: math.Inf(-1), hi: math.Inf(1)}
+	var err error
+	if from != unbounded {
+		if kind == KindDate {
+			m.lo, _, err = parseDate(from)
+		} else {
+			m.lo, err = parseNumber(from)
+		}
+		if err != nil {
+			return m, err
+		}
+	}
+	if to != unbounded {
+		if kind == KindDate {
+			_, m.hi, err = parseDate(to)
+		} else {
+			m.hi, err = parseNumber(to)
+		}
+		if err != nil {
+			return m, err
+		}
+	}
+	if m.lo > m.hi {
+		return m, fmt.Errorf("range starts after it ends")
+	}
+	return m, nil
+}
+
+// dateLayouts are the accepted date formats with the period each one spans
+var dateLayouts = []struct {
+	layout string
+	next   func(time.Time) time.Time
+}{
+	{"2006-01-02T15:04:05", func(t time.Time) time.Time { return t.Add(time.Second) }},
+	{"2006-01-02T15:04", func(t time.Time) time.Time { return t.Add(time.Minute) }},
+	{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+// parseDate returns the first and last unix millisecond of the local-time
+// period value denotes, e.g. the whole day for 2024-01-15. "today" and
+// "yesterday" are understood too.
+func parseDate(value string) (lo, hi float64, err error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var start, end time.Time
+	switch strings.ToLower(value) {
+	case "today":
+		start, end = today, today.AddDate(0, 0, 1)
+	case "yesterday":
+		start, end = today.AddDate(0, 0, -1), today
+	default:
+		for _, l := range dateLayouts {
+			t, err := time.ParseInLocation(l.layout, value, time.Local)
+			if err == nil {
+				start, end = t, l.next(t)
+				break
+			}
+		}
+		if start.IsZero() {
+			return 0, 0, fmt.Errorf("%q is not a date, use YYYY-MM-DD", value)
+		}
+	}
+	return float64(start.UnixMilli()), float64(end.UnixMilli() - 1), nil
+}
+
+func parseNumber(value string) (float64, error) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return n, nil
+}
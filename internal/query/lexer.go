@@ -0,0 +1,118 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokWord             // bare word
+	tokQuoted           // {multi word} or "multi word"
+	tokColon            // :
+	tokComma            // ,
+	tokHash             // # starting a tag
+	tokMinus            // - negating the next term or value
+	tokRange            // ..
+	tokLParen           // (
+	tokRParen           // )
+)
+
+// token is a lexical token; start and end are rune offsets into the query
+type token struct {
+	kind       tokenKind
+	text       string
+	start, end int
+}
+
+// is reports whether t is the bare keyword kw, ignoring case
+func (t token) is(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+// lex splits a query into tokens. Unterminated quotes are reported as
+// errors and run to the end of the query; a stray } is reported and skipped.
+func lex(query string) ([]token, []Error) {
+	var (
+		tokens []token
+		errs   []Error
+	)
+	rs := []rune(query)
+	emit := func(kind tokenKind, text string, start, end int) {
+		tokens = append(tokens, token{kind: kind, text: text, start: start, end: end})
+	}
+	// atTermStart reports whether a term can start at i, so - and # are
+	// operators there. A - before i was lexed as an operator, as words
+	// consume their inner dashes: -#Unresolved.
+	atTermStart := func(i int) bool {
+		return i == 0 || unicode.IsSpace(rs[i-1]) || strings.ContainsRune("(:,-", rs[i-1])
+	}
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			emit(tokLParen, "(", i, i+1)
+			i++
+		case r == ')':
+			emit(tokRParen, ")", i, i+1)
+			i++
+		case r == ':':
+			emit(tokColon, ":", i, i+1)
+			i++
+		case r == ',':
+			emit(tokComma, ",", i, i+1)
+			i++
+		case r == '.' && i+1 < len(rs) && rs[i+1] == '.':
+			emit(tokRange, "..", i, i+2)
+			i += 2
+		case r == '-' && atTermStart(i) && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]):
+			emit(tokMinus, "-", i, i+1)
+			i++
+		case r == '#' && atTermStart(i):
+			emit(tokHash, "#", i, i+1)
+			i++
+		case r == '}':
+			errs = append(errs, Error{Start: i, End: i + 1, Message: "unexpected }"})
+			i++
+		case r == '{' || r == '"':
+			closing := '}'
+			if r == '"' {
+				closing = '"'
+			}
+			j := i + 1
+			for j < len(rs) && rs[j] != closing {
+				j++
+			}
+			if j == len(rs) {
+				errs = append(errs, Error{Start: i, End: j, Message: "missing closing " + string(closing)})
+				emit(tokQuoted, string(rs[i+1:j]), i, j)
+				i = j
+				continue
+			}
+			emit(tokQuoted, string(rs[i+1:j]), i, j+1)
+			i = j + 1
+		default:
+			j := i
+			for j < len(rs) && !endsWord(rs, j) {
+				j++
+			}
+			emit(tokWord, string(rs[i:j]), i, j)
+			i = j
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, start: len(rs), end: len(rs)})
+	return tokens, errs
+}
+
+// endsWord reports whether a bare word ends before rs[i]
+func endsWord(rs []rune, i int) bool {
+	r := rs[i]
+	if unicode.IsSpace(r) || strings.ContainsRune(":,(){}\"", r) {
+		return true
+	}
+	return r == '.' && i+1 < len(rs) && rs[i+1] == '.'
+}
@@ -0,0 +1,363 @@
+// Package query parses a practical subset of the YouTrack query language and
+// evaluates it against locally cached records:
+//
+//	#Unresolved Type: Bug, Feature Priority: -Minor for: me login page
+//	created: 2024-01-01 .. * (State: Open or State: {In Progress})
+//
+// Supported are field: value pairs with comma-separated alternatives,
+// #tag shorthands, negation with -, or with parentheses, quoted values in
+// {braces} or "double quotes", date and number ranges with .. (* leaves a
+// side open), and "sort by: field [asc|desc], ..." clauses. Words that aren't part of a term are free text, which is
+// left to the caller to rank.
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is how values of a field are compared
+type Kind int
+
+const (
+	KindEnum   Kind = iota // equal, ignoring case: Type: Bug
+	KindText               // contains, ignoring case: summary: login
+	KindUser               // login or full name; "me" is the current user
+	KindDate               // unix millis; a date matches that whole day
+	KindNumber             // exact number or range
+)
+
+// Fields looks up a field by its lower-cased name and returns the name the
+// Record knows it by
+type Fields func(name string) (field string, kind Kind, ok bool)
+
+// Record is what a query is evaluated against
+type Record interface {
+	// Values returns the values of a field by the name Fields returned.
+	// Excluded words (-word) are looked up in the "summary" field.
+	Values(field string) []string
+	// Number returns the value of a date or number field; ok is false if it is empty
+	Number(field string) (n float64, ok bool)
+	// Resolved reports whether the record is resolved, for #Resolved and #Unresolved
+	Resolved() bool
+	// TagValues returns the values any other #tag is compared with
+	TagValues() []string
+}
+
+// Env holds what evaluation needs besides the record
+type Env struct {
+	// Me returns the login of the current user; it is called when a query
+	// refers to "me". While it is nil or returns "", "me" matches nothing.
+	Me func() string
+}
+
+// Error is a syntax problem in the query. Start and End are rune offsets of
+// the text to underline.
+type Error struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s (at %d)", e.Message, e.Start)
+}
+
+// Query is a parsed query
+type Query struct {
+	Text   string  // free-text words, space separated
+	Errors []Error // problems found; the terms they concern are ignored
+	filter expr    // nil matches everything
+	sort   []sortKey
+}
+
+// Filtered reports whether the query has any field or tag terms
+func (q *Query) Filtered() bool {
+	return q.filter != nil
+}
+
+// Match reports whether r satisfies the query's field and tag terms. Free
+// text is not considered.
+func (q *Query) Match(r Record, env Env) bool {
+	return q.filter == nil || q.filter.match(r, env)
+}
+
+// Sorted reports whether the query has a sort by: clause
+func (q *Query) Sorted() bool {
+	return len(q.sort) > 0
+}
+
+// Compare orders records by the query's sort by: clauses, like cmp.Compare.
+// Records that are equal by all keys compare as 0, leaving them in relevance
+// order for a stable sort.
+func (q *Query) Compare(a, b Record) int {
+	for _, k := range q.sort {
+		if c := k.compare(a, b); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Parse parses query. It never fails: invalid terms are reported in
+// Query.Errors and left out of the filter.
+func Parse(query string, fields Fields) *Query {
+	tokens, errs := lex(query)
+	p := &parser{tokens: tokens, fields: fields, errs: errs}
+	var terms []expr
+	for {
+		if e := p.parseOr(); e != nil {
+			terms = append(terms, e)
+		}
+		if p.peek().kind == tokEOF {
+			break
+		}
+		// parseOr only stops early at a ) without matching (
+		t := p.next()
+		p.errorAt(t, "unmatched )")
+	}
+	return &Query{Text: strings.Join(p.text, " "), Errors: p.errs, filter: and(terms), sort: p.sort}
+}
+
+// parser is a recursive descent parser over the lexed tokens. Precedence from
+// loosest to tightest: or, implicit and, -, then terms and parentheses.
+type parser struct {
+	tokens []token
+	pos    int
+	fields Fields
+	errs   []Error
+	text   []string
+	sort   []sortKey
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, format string, args ...any) {
+	p.errs = append(p.errs, Error{Start: t.start, End: t.end, Message: fmt.Sprintf(format, args...)})
+}
+
+// parseOr parses terms joined by or
+func (p *parser) parseOr() expr {
+	var (
+		alts []expr
+		text []bool // whether the alternative held free text only
+		ors  []token
+	)
+	for {
+		before := len(p.text)
+		e := p.parseAnd()
+		alts = append(alts, e)
+		text = append(text, e == nil && len(p.text) > before)
+		if !p.peek().is("or") {
+			break
+		}
+		ors = append(ors, p.next())
+	}
+	if len(ors) == 0 {
+		return alts[0]
+	}
+	for i, or := range ors {
+		switch {
+		case text[i] || text[i+1]:
+			p.errorAt(or, `"or" only combines field and tag terms; free text applies to the whole query`)
+		case alts[i] == nil || alts[i+1] == nil:
+			p.errorAt(or, `"or" needs a term on both sides`)
+		}
+	}
+	var or orExpr
+	for _, e := range alts {
+		if e != nil {
+			or = append(or, e)
+		}
+	}
+	switch len(or) {
+	case 0:
+		return nil
+	case 1:
+		return or[0]
+	}
+	return or
+}
+
+// parseAnd parses a sequence of terms up to or, ) or the end
+func (p *parser) parseAnd() expr {
+	var terms []expr
+	for {
+		t := p.peek()
+		if t.kind == tokEOF || t.kind == tokRParen || t.is("or") {
+			break
+		}
+		if t.is("and") {
+			p.next()
+			continue
+		}
+		if e := p.parseUnary(); e != nil {
+			terms = append(terms, e)
+		}
+	}
+	return and(terms)
+}
+
+// parseUnary parses a term with an optional leading -
+func (p *parser) parseUnary() expr {
+	if p.peek().kind != tokMinus {
+		return p.parseTerm()
+	}
+	minus := p.next()
+	t := p.peek()
+	if (t.kind == tokWord || t.kind == tokQuoted) && p.tokens[p.pos+1].kind != tokColon {
+		// -word excludes records whose summary contains it
+		p.next()
+		return notExpr{textExpr{word: strings.ToLower(t.text)}}
+	}
+	e := p.parseTerm()
+	if e == nil {
+		p.errorAt(minus, "nothing to exclude after -")
+		return nil
+	}
+	return notExpr{e}
+}
+
+// parseTerm parses a parenthesized group, #tag, field: values or a free-text word
+func (p *parser) parseTerm() expr {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		e := p.parseOr()
+		if p.peek().kind != tokRParen {
+			p.errorAt(t, "missing )")
+			return e
+		}
+		p.next()
+		return e
+	case tokHash:
+		v := p.peek()
+		if v.kind != tokWord && v.kind != tokQuoted {
+			p.errorAt(t, "missing tag after #")
+			return nil
+		}
+		p.next()
+		return tagExpr{tag: strings.ToLower(v.text)}
+	case tokWord, tokQuoted:
+		if t.is("sort") && p.peek().is("by") && p.tokens[p.pos+1].kind == tokColon {
+			p.parseSort()
+			return nil
+		}
+		if p.peek().kind == tokColon {
+			return p.parseField(t)
+		}
+		p.text = append(p.text, t.text)
+		return nil
+	case tokColon:
+		p.errorAt(t, "missing field name before :")
+	case tokRange:
+		p.errorAt(t, ".. needs a field, e.g. created: 2024-01-01 .. 2024-01-31")
+	case tokMinus:
+		p.errorAt(t, "- must be followed by a term")
+	}
+	// Stray commas are ignored like YouTrack does
+	return nil
+}
+
+// parseField parses the comma-separated values after "name:"
+func (p *parser) parseField(name token) expr {
+	colon := p.next()
+	field, kind, known := p.fields(strings.ToLower(name.text))
+	if !known {
+		p.errorAt(name, "unknown field %q", name.text)
+	}
+	f := fieldExpr{field: field, kind: kind}
+	valid := known
+	for {
+		negate := false
+		if p.peek().kind == tokMinus {
+			p.next()
+			negate = true
+		}
+		v := p.peek()
+		if v.kind != tokWord && v.kind != tokQuoted {
+			p.errorAt(colon, "missing value for %s", name.text)
+			return nil
+		}
+		p.next()
+		var m valueMatcher
+		var err error
+		if p.peek().kind == tokRange {
+			rangeTok := p.next()
+			hi := p.peek()
+			if hi.kind != tokWord && hi.kind != tokQuoted {
+				p.errorAt(rangeTok, "missing end of range")
+				return nil
+			}
+			p.next()
+			if known {
+				m, err = rangeMatcher(kind, v.text, hi.text)
+				if err != nil {
+					p.errs = append(p.errs, Error{Start: v.start, End: hi.end, Message: err.Error()})
+					valid = false
+				}
+			}
+		} else if known {
+			m, err = valueMatcherFor(kind, v.text)
+			if err != nil {
+				p.errorAt(v, "%s", err)
+				valid = false
+			}
+		}
+		if negate {
+			f.exclude = append(f.exclude, m)
+		} else {
+			f.include = append(f.include, m)
+		}
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if !valid {
+		return nil
+	}
+	return f
+}
+
+// parseSort parses the comma-separated keys after "sort by:"
+func (p *parser) parseSort() {
+	p.next() // by
+	colon := p.next()
+	for {
+		name := p.peek()
+		if name.kind != tokWord && name.kind != tokQuoted {
+			p.errorAt(colon, "missing field after sort by:")
+			return
+		}
+		p.next()
+		field, kind, known := p.fields(strings.ToLower(name.text))
+		k := sortKey{field: field, kind: kind, desc: true}
+		switch {
+		case p.peek().is("asc"):
+			p.next()
+			k.desc = false
+		case p.peek().is("desc"):
+			p.next()
+		}
+		if known {
+			p.sort = append(p.sort, k)
+		} else {
+			p.errorAt(name, "unknown field %q", name.text)
+		}
+		if p.peek().kind != tokComma {
+			return
+		}
+		p.next()
+	}
+}
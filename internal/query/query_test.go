@@ -0,0 +1,272 @@
+package query
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testRecord is a Record backed by maps
+type testRecord struct {
+	id       string
+	values   map[string][]string
+	numbers  map[string]float64
+	resolved bool
+}
+
+func (r testRecord) Values(field string) []string { return r.values[field] }
+
+func (r testRecord) Number(field string) (float64, bool) {
+	n, ok := r.numbers[field]
+	return n, ok
+}
+
+func (r testRecord) Resolved() bool { return r.resolved }
+
+func (r testRecord) TagValues() []string {
+	return append(append([]string{}, r.values["type"]...), r.values["priority"]...)
+}
+
+var testFields Fields = func(name string) (string, Kind, bool) {
+	kinds := map[string]Kind{
+		"type":     KindEnum,
+		"priority": KindEnum,
+		"state":    KindEnum,
+		"summary":  KindText,
+		"for":      KindUser,
+		"created":  KindDate,
+		"votes":    KindNumber,
+	}
+	kind, ok := kinds[name]
+	return name, kind, ok
+}
+
+func millis(year int, month time.Month, day, hour, min, sec int) float64 {
+	return float64(time.Date(year, month, day, hour, min, sec, 0, time.Local).UnixMilli())
+}
+
+var testRecords = []testRecord{
+	{
+		id: "AGV-1",
+		values: map[string][]string{
+			"type": {"Bug"}, "priority": {"Critical"}, "state": {"Open"},
+			"summary": {"Login page crashes"}, "for": {"jdoe", "Jane Doe"},
+		},
+		numbers: map[string]float64{"created": millis(2024, 1, 15, 12, 0, 0), "votes": 5},
+	},
+	{
+		id: "AGV-2",
+		values: map[string][]string{
+			"type": {"Feature"}, "priority": {"Minor"}, "state": {"In Progress"},
+			"summary": {"Export reports"}, "for": {"", ""}, // unassigned
+		},
+		numbers:  map[string]float64{"created": millis(2024, 2, 1, 9, 0, 0), "votes": 1},
+		resolved: true,
+	},
+	{
+		id: "JU-3",
+		values: map[string][]string{
+			"type": {"Bug"}, "priority": {"Major"}, "state": {"Fixed"},
+			"summary": {"Login timeout"}, "for": {"asmith", "Alex Smith"},
+		},
+		numbers:  map[string]float64{"created": millis(2023, 12, 31, 23, 59, 59)},
+		resolved: true,
+	},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		me    *string // nil leaves Env.Me unset
+		want  []string
+	}{
+		{query: "", want: []string{"AGV-1", "AGV-2", "JU-3"}},
+		{query: "Type: Bug", want: []string{"AGV-1", "JU-3"}},
+		{query: "type: bug", want: []string{"AGV-1", "JU-3"}},
+		{query: "Type: Bug, Feature", want: []string{"AGV-1", "AGV-2", "JU-3"}},
+		{query: "login Type: Bug", want: []string{"AGV-1", "JU-3"}},
+		{query: "summary: page", want: []string{"AGV-1"}},
+
+		// Quoting
+		{query: "State: {In Progress}", want: []string{"AGV-2"}},
+		{query: `State: "In Progress"`, want: []string{"AGV-2"}},
+		{query: "State: In Progress", want: nil}, // Progress is free text
+		{query: "for: {Jane Doe}", want: []string{"AGV-1"}},
+
+		// Negation
+		{query: "Type: -Bug", want: []string{"AGV-2"}},
+		{query: "-Type: Bug", want: []string{"AGV-2"}},
+		{query: "Priority: -Minor, -Major", want: []string{"AGV-1"}},
+		{query: "-login", want: []string{"AGV-2"}},
+		{query: "-{login page}", want: []string{"AGV-2", "JU-3"}},
+		{query: "-(Type: Bug or State: Fixed)", want: []string{"AGV-2"}},
+
+		// or binds looser than implicit and; parentheses group
+		{query: "Type: Bug or Priority: Minor", want: []string{"AGV-1", "AGV-2", "JU-3"}},
+		{query: "Type: Bug Priority: Critical or Priority: Minor", want: []string{"AGV-1", "AGV-2"}},
+		{query: "Type: Bug (Priority: Critical or Priority: Minor)", want: []string{"AGV-1"}},
+		{query: "(Type: Bug and State: Fixed) or #Unresolved", want: []string{"AGV-1", "JU-3"}},
+
+		// Tags
+		{query: "#Unresolved", want: []string{"AGV-1"}},
+		{query: "#resolved", want: []string{"AGV-2", "JU-3"}},
+		{query: "#Unresolved Type: Bug", want: []string{"AGV-1"}},
+		{query: "-#Unresolved", want: []string{"AGV-2", "JU-3"}},
+		{query: "#Bug", want: []string{"AGV-1", "JU-3"}},
+		{query: "#Major", want: []string{"JU-3"}},
+
+		// Dates match whole periods; ranges are inclusive and * leaves a side open
+		{query: "created: 2024-01-15", want: []string{"AGV-1"}},
+		{query: "created: 2024-01", want: []string{"AGV-1"}},
+		{query: "created: 2024-01-01 .. *", want: []string{"AGV-1", "AGV-2"}},
+		{query: "created: * .. 2023-12-31", want: []string{"JU-3"}},
+		{query: "created: 2024-01-15 .. 2024-02-01", want: []string{"AGV-1", "AGV-2"}},
+		{query: "created: 2024", want: []string{"AGV-1", "AGV-2"}},
+		{query: "votes: 1", want: []string{"AGV-2"}},
+		{query: "votes: 2 .. 10", want: []string{"AGV-1"}},
+		{query: "votes: * .. 5", want: []string{"AGV-1", "AGV-2"}},
+
+		// Terms with errors are left out of the filter
+		{query: "foo: bar Type: Bug", want: []string{"AGV-1", "JU-3"}},
+		{query: "created: 2024-13-01 Type: Feature", want: []string{"AGV-2"}},
+
+		// me
+		{query: "for: jdoe", want: []string{"AGV-1"}},
+		{query: "for: me", me: ptr("jdoe"), want: []string{"AGV-1"}},
+		{query: "for: me", me: ptr("JDoe"), want: []string{"AGV-1"}},
+		{query: "for: me", me: ptr(""), want: nil},
+		{query: "for: me", want: nil},
+		{query: "for: me, asmith", me: ptr(""), want: []string{"JU-3"}},
+		{query: "summary: me", me: ptr("jdoe"), want: []string{"JU-3"}}, // literal outside user fields: "timeout"
+	}
+	for _, tt := range tests {
+		var env Env
+		if tt.me != nil {
+			env.Me = func() string { return *tt.me }
+		}
+		q := Parse(tt.query, testFields)
+		var got []string
+		for _, r := range testRecords {
+			if q.Match(r, env) {
+				got = append(got, r.id)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			me := "<nil>"
+			if tt.me != nil {
+				me = *tt.me
+			}
+			t.Errorf("Parse(%q) with me=%q matches %v, want %v", tt.query, me, got, tt.want)
+		}
+	}
+}
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		query    string
+		text     string
+		filtered bool
+		sorted   bool
+	}{
+		{query: "login page", text: "login page"},
+		{query: `login "multi word" Type: Bug page`, text: "login multi word page", filtered: true},
+		{query: "{In Progress}", text: "In Progress"},
+		{query: "#Unresolved", filtered: true},
+		{query: "-login", filtered: true},
+		{query: "a-b x-1", text: "a-b x-1"},
+		{query: "AGV-952 and crash", text: "AGV-952 crash"},
+		{query: "crash sort by: created", text: "crash", sorted: true},
+		{query: "sort by", text: "sort by"},
+		{query: "sort by: foo", sorted: false},
+		{query: "a } b", text: "a b"},
+	}
+	for _, tt := range tests {
+		q := Parse(tt.query, testFields)
+		if q.Text != tt.text || q.Filtered() != tt.filtered || q.Sorted() != tt.sorted {
+			t.Errorf("Parse(%q): text %q, filtered %v, sorted %v; want %q, %v, %v",
+				tt.query, q.Text, q.Filtered(), q.Sorted(), tt.text, tt.filtered, tt.sorted)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []Error
+	}{
+		{query: "Type: Bug #Unresolved (State: Open or State: Fixed)", want: nil},
+		{query: "foo: bar", want: []Error{{0, 3, `unknown field "foo"`}}},
+		{query: "Ümlaut: x", want: []Error{{0, 6, `unknown field "Ümlaut"`}}}, // rune offsets
+		{query: "login Type:", want: []Error{{10, 11, "missing value for Type"}}},
+		{query: "State: {In Progress", want: []Error{{7, 19, "missing closing }"}}},
+		{query: `summary: "login`, want: []Error{{9, 15, `missing closing "`}}},
+		{query: "}", want: []Error{{0, 1, "unexpected }"}}},
+		{query: "a }", want: []Error{{2, 3, "unexpected }"}}},
+		{query: "State: Open} login", want: []Error{{11, 12, "unexpected }"}}},
+		{query: "(Type: Bug", want: []Error{{0, 1, "missing )"}}},
+		{query: "Type: Bug)", want: []Error{{9, 10, "unmatched )"}}},
+		{query: "Type: Bug or", want: []Error{{10, 12, `"or" needs a term on both sides`}}},
+		{query: "login or Type: Bug", want: []Error{{6, 8, `"or" only combines field and tag terms; free text applies to the whole query`}}},
+		{query: "x #", want: []Error{{2, 3, "missing tag after #"}}},
+		{query: "-#", want: []Error{{1, 2, "missing tag after #"}, {0, 1, "nothing to exclude after -"}}},
+		{query: ": Bug", want: []Error{{0, 1, "missing field name before :"}}},
+		{query: ".. 2024", want: []Error{{0, 2, ".. needs a field, e.g. created: 2024-01-01 .. 2024-01-31"}}},
+		{query: "created: 2024-13-01", want: []Error{{9, 19, `"2024-13-01" is not a date, use YYYY-MM-DD`}}},
+		{query: "votes: many", want: []Error{{7, 11, `"many" is not a number`}}},
+		{query: "created: 2024-01-01 ..", want: []Error{{20, 22, "missing end of range"}}},
+		{query: "created: 2024-02-01 .. 2024-01-01", want: []Error{{9, 33, "range starts after it ends"}}},
+		{query: "Type: Bug .. Feature", want: []Error{{6, 20, "ranges only work on date and number fields"}}},
+		{query: "sort by:", want: []Error{{7, 8, "missing field after sort by:"}}},
+		{query: "sort by: created, foo desc", want: []Error{{18, 21, `unknown field "foo"`}}},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.query, testFields).Errors; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) errors = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "sort by: created", want: []string{"AGV-2", "AGV-1", "JU-3"}},
+		{query: "sort by: created desc", want: []string{"AGV-2", "AGV-1", "JU-3"}},
+		{query: "sort by: Created asc", want: []string{"JU-3", "AGV-1", "AGV-2"}},
+		// JU-3 has no votes and comes last either way
+		{query: "sort by: votes asc", want: []string{"AGV-2", "AGV-1", "JU-3"}},
+		{query: "sort by: votes", want: []string{"AGV-1", "AGV-2", "JU-3"}},
+		// Text values sort by their first value; unassigned comes last
+		{query: "sort by: for asc", want: []string{"JU-3", "AGV-1", "AGV-2"}},
+		{query: "sort by: for", want: []string{"AGV-1", "JU-3", "AGV-2"}},
+		{query: "sort by: type asc, created asc", want: []string{"JU-3", "AGV-1", "AGV-2"}},
+		{query: "sort by: type, priority asc", want: []string{"AGV-2", "AGV-1", "JU-3"}},
+		{query: "Type: Bug sort by: created asc", want: []string{"JU-3", "AGV-1"}},
+		{query: "sort by: votes asc #Resolved", want: []string{"AGV-2", "JU-3"}},
+		// Unknown keys are skipped; without a valid key the order is kept
+		{query: "sort by: foo, summary asc", want: []string{"AGV-2", "AGV-1", "JU-3"}},
+		{query: "sort by: foo", want: []string{"AGV-1", "AGV-2", "JU-3"}},
+		{query: "sort by: type", want: []string{"AGV-2", "AGV-1", "JU-3"}}, // the two bugs keep their order
+	}
+	for _, tt := range tests {
+		q := Parse(tt.query, testFields)
+		var records []testRecord
+		for _, r := range testRecords {
+			if q.Match(r, Env{}) {
+				records = append(records, r)
+			}
+		}
+		slices.SortStableFunc(records, func(a, b testRecord) int { return q.Compare(a, b) })
+		var got []string
+		for _, r := range records {
+			got = append(got, r.id)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) sorts %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func ptr(s string) *string { return &s }
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Normalization selects how words are normalized before matching. Documents
+// and queries go through the same steps, so either side may use any spelling.
+type Normalization struct {
+	FoldUmlauts     bool // ä, ö, ü and ß also match ae, oe, ue and ss
+	StripDiacritics bool // é matches e, ö matches o, and so on
+	Stem            bool // strip common German and English inflection suffixes
+	SplitCompounds  bool // match the parts of compound words, e.g. "anpassung" in "Bestandsanpassung"
+}
+
+// DefaultNormalization enables every step
+var DefaultNormalization = Normalization{FoldUmlauts: true, StripDiacritics: true, Stem: true, SplitCompounds: true}
+
+// form is one spelling under which a word is indexed or looked up
+type form struct {
+	text string
+	stem bool // text is a stem, so matches on it rank a little lower
+}
+
+// Match quality factors for normalized forms
+const (
+	stemFactor        = 0.9 // multiplied with the quality of a match involving a stem
+	qualityCompound   = 0.7 // query word is the trailing part of a compound
+	minCompoundPart   = 4   // runes a compound part needs to be matched or split off
+	minStemmedLength  = 3   // runes that must remain after removing a suffix
+	minCompoundLength = 2 * minCompoundPart
+)
+
+var umlautDigraphs = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
+// diacritics maps accented lower-case Latin letters to their base letter
+var diacritics = func() map[rune]rune {
+	const (
+		accented = "àáâãäåāăąçćčďđèéêëēėęěìíîïīįıłńňñòóôõöøōőŕřśšşșțţťùúûüūůűųýÿźżž"
+		base     = "aaaaaaaaacccddeeeeeeeeiiiiiiilnnnoooooooorrsssstttuuuuuuuuyyzzz"
+	)
+	m := make(map[rune]rune, len(base))
+	b := []rune(base)
+	for i, r := range []rune(accented) {
+		m[r] = b[i]
+	}
+	return m
+}()
+
+// stemSuffixes are tried in order; the first one found is removed or replaced.
+// It is the union of common English and German inflection endings.
+var stemSuffixes = []struct{ suffix, replace string }{
+	{"ies", "y"}, {"ing", ""}, {"ern", ""}, {"ed", ""}, {"ly", ""},
+	{"en", ""}, {"er", ""}, {"em", ""}, {"es", ""}, {"e", ""}, {"s", ""},
+}
+
+// forms returns the spellings word is indexed and looked up under: word
+// itself first, then its folded spellings and their stems, without duplicates.
+func (n Normalization) forms(word string) []form {
+	forms := []form{{text: word}}
+	add := func(text string, stem bool) {
+		for _, f := range forms {
+			if f.text == text {
+				return
+			}
+		}
+		forms = append(forms, form{text: text, stem: stem})
+	}
+	if n.FoldUmlauts {
+		add(umlautDigraphs.Replace(word), false)
+	}
+	if n.StripDiacritics {
+		add(stripDiacritics(word), false)
+	}
+	if n.Stem && !containsDigit(word) {
+		for _, f := range forms {
+			add(stem(f.text), true)
+		}
+	}
+	return forms
+}
+
+// stripDiacritics replaces accented letters with their base letter
+func stripDiacritics(word string) string {
+	return strings.Map(func(r rune) rune {
+		if b, ok := diacritics[r]; ok {
+			return b
+		}
+		return r
+	}, word)
+}
+
+// stem removes one inflection suffix from word, keeping at least
+// minStemmedLength runes
+func stem(word string) string {
+	for _, s := range stemSuffixes {
+		if !strings.HasSuffix(word, s.suffix) {
+			continue
+		}
+		rest := word[:len(word)-len(s.suffix)]
+		if utf8.RuneCountInString(rest) < minStemmedLength {
+			continue
+		}
+		return rest + s.replace
+	}
+	return word
+}
+
+// compoundPart reports whether q is the trailing part of the compound term,
+// e.g. "anpassung" in "bestandsanpassung"
+func compoundPart(q, term string) bool {
+	qLen := utf8.RuneCountInString(q)
+	return qLen >= minCompoundPart && utf8.RuneCountInString(term)-qLen >= minCompoundPart-1 &&
+		strings.HasSuffix(term, q)
+}
+
+// splitCompound splits a query word the index doesn't know into two known
+// words, e.g. "bestandsanpassung" into "bestand" and "anpassung". A linking
+// "s" between the parts is dropped. known reports whether a word is indexed.
+func splitCompound(word string, known func(string) bool) (head, tail string, ok bool) {
+	runes := []rune(word)
+	if len(runes) < minCompoundLength || known(word) {
+		return "", "", false
+	}
+	// Prefer the longest tail, it is usually the compound's head noun
+	for i := minCompoundPart; i <= len(runes)-minCompoundPart; i++ {
+		head, tail = string(runes[:i]), string(runes[i:])
+		if !known(tail) {
+			continue
+		}
+		if known(head) {
+			return head, tail, true
+		}
+		if h := strings.TrimSuffix(head, "s"); h != head && utf8.RuneCountInString(h) >= minCompoundPart && known(h) {
+			return h, tail, true
+		}
+	}
+	return "", "", false
+}
+
+func containsDigit(s string) bool {
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,562 @@
+// Package search is an in-memory ticket search index with typo-tolerant
+// matching, ID prefix and number lookups, relevance scores and highlights.
+package search
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Document is the searchable part of a ticket
+type Document struct {
+	ID       string
+	Summary  string
+	Type     string
+	Priority string
+	Sprints  []string
+}
+
+// Fields a Highlight can refer to
+const (
+	FieldID       = "id"
+	FieldSummary  = "summary"
+	FieldType     = "type"
+	FieldPriority = "priority"
+	FieldSprints  = "sprints"
+)
+
+// Score weights. They follow the weights the frontend used: an ID hit beats a
+// summary hit, which beats type, priority and sprint hits.
+const (
+	scoreIDExact    = 1000
+	scoreIDNumber   = 900 // "952" for AGV-952
+	scoreIDPrefix   = 800
+	scoreIDNumberPx = 700 // "95" for AGV-952
+	scoreIDContains = 600
+)
+
+var fieldWeight = map[string]float64{
+	FieldSummary:  400,
+	FieldType:     200,
+	FieldPriority: 100,
+	FieldSprints:  100,
+}
+
+// Term match qualities, multiplied with the field weight
+const (
+	qualityExact     = 1.0
+	qualityPrefix    = 0.8
+	qualityFuzzy     = 0.6 // minus fuzzyPenalty per edit beyond the first
+	fuzzyPenalty     = 0.15
+	qualitySubstring = 0.5
+)
+
+// Highlight marks a matched range of a field value. Start and End are
+// character (rune) offsets; Index is the element for sprints, 0 otherwise.
+type Highlight struct {
+	Field string `json:"field"`
+	Index int    `json:"index"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Boost returns extra score for a document, e.g. from usage history. It is
+// added to the relevance of matches and orders the empty-query listing.
+type Boost func(id string) float64
+
+// Result is one search hit
+type Result struct {
+	ID         string
+	Score      float64
+	Highlights []Highlight
+}
+
+// Index is a search index over Documents. It is safe for concurrent use.
+type Index struct {
+	mu    sync.RWMutex
+	norm  Normalization
+	docs  map[string]*entry
+	terms map[string]map[string]bool // word form → IDs of documents containing it
+}
+
+// entry is an indexed document
+type entry struct {
+	doc     Document
+	idLower string
+	number  string // digits after the last '-', e.g. "952"
+	tokens  []token
+}
+
+// token is one word of a document field or query
+type token struct {
+	term  string // lower-cased word
+	forms []form // spellings of term it matches under, term first
+	field string
+	index int // sprint index
+	start int // rune offsets in the field value
+	end   int
+}
+
+// NewIndex returns an empty index using DefaultNormalization
+func NewIndex() *Index {
+	return &Index{norm: DefaultNormalization, docs: map[string]*entry{}, terms: map[string]map[string]bool{}}
+}
+
+// SetNormalization changes how words are normalized and re-indexes all documents
+func (ix *Index) SetNormalization(n Normalization) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if n == ix.norm {
+		return
+	}
+	ix.norm = n
+	docs := ix.docs
+	ix.docs = make(map[string]*entry, len(docs))
+	ix.terms = map[string]map[string]bool{}
+	for _, e := range docs {
+		ix.add(e.doc)
+	}
+}
+
+// Len returns the number of indexed documents
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Reset replaces the whole index contents with docs
+func (ix *Index) Reset(docs []Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs = make(map[string]*entry, len(docs))
+	ix.terms = map[string]map[string]bool{}
+	for _, d := range docs {
+		ix.add(d)
+	}
+}
+
+// Upsert adds documents or replaces them by ID
+func (ix *Index) Upsert(docs ...Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, d := range docs {
+		ix.remove(d.ID)
+		ix.add(d)
+	}
+}
+
+// Delete removes documents by ID
+func (ix *Index) Delete(ids ...string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, id := range ids {
+		ix.remove(id)
+	}
+}
+
+// add indexes d. Callers hold ix.mu.
+func (ix *Index) add(d Document) {
+	e := &entry{doc: d, idLower: strings.ToLower(d.ID)}
+	if i := strings.LastIndex(d.ID, "-"); i >= 0 {
+		e.number = d.ID[i+1:]
+	}
+	e.tokens = append(e.tokens, tokenize(d.Summary, FieldSummary, 0)...)
+	e.tokens = append(e.tokens, tokenize(d.Type, FieldType, 0)...)
+	e.tokens = append(e.tokens, tokenize(d.Priority, FieldPriority, 0)...)
+	for i, s := range d.Sprints {
+		e.tokens = append(e.tokens, tokenize(s, FieldSprints, i)...)
+	}
+	ix.docs[d.ID] = e
+	for i := range e.tokens {
+		t := &e.tokens[i]
+		t.forms = ix.norm.forms(t.term)
+		for _, f := range t.forms {
+			ids := ix.terms[f.text]
+			if ids == nil {
+				ids = map[string]bool{}
+				ix.terms[f.text] = ids
+			}
+			ids[d.ID] = true
+		}
+	}
+}
+
+// remove drops the document with the given ID. Callers hold ix.mu.
+func (ix *Index) remove(id string) {
+	e, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	delete(ix.docs, id)
+	for _, t := range e.tokens {
+		for _, f := range t.forms {
+			if ids := ix.terms[f.text]; ids != nil {
+				delete(ids, id)
+				if len(ids) == 0 {
+					delete(ix.terms, f.text)
+				}
+			}
+		}
+	}
+}
+
+// queryTokens tokenizes and normalizes query. With SplitCompounds, words the
+// index doesn't know are split into known parts that must all match.
+// Callers hold ix.mu.
+func (ix *Index) queryTokens(query string) []token {
+	var tokens []token
+	for _, t := range tokenize(query, "", 0) {
+		if ix.norm.SplitCompounds {
+			if head, tail, ok := splitCompound(t.term, ix.known); ok {
+				tokens = append(tokens, token{term: head}, token{term: tail})
+				continue
+			}
+		}
+		tokens = append(tokens, t)
+	}
+	for i := range tokens {
+		tokens[i].forms = ix.norm.forms(tokens[i].term)
+	}
+	return tokens
+}
+
+// known reports whether word is indexed under any of its forms. Callers hold ix.mu.
+func (ix *Index) known(word string) bool {
+	for _, f := range ix.norm.forms(word) {
+		if len(ix.terms[f.text]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits a field value into lower-cased words of letters and digits
+func tokenize(text, field string, index int) []token {
+	var tokens []token
+	var word []rune
+	start := 0
+	pos := 0
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, token{term: string(word), field: field, index: index, start: start, end: pos})
+			word = word[:0]
+		}
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if len(word) == 0 {
+				start = pos
+			}
+			word = append(word, unicode.ToLower(r))
+		} else {
+			flush()
+		}
+		pos++
+	}
+	flush()
+	return tokens
+}
+
+// hit accumulates the score of one document while searching
+type hit struct {
+	entry      *entry
+	idScore    float64
+	termScores []float64 // best score per query term
+	highlights []Highlight
+}
+
+// Search returns the limit best matches for query, best first. An empty
+// query lists documents by descending boost, then ticket number. limit <= 0
+// means no limit; boost may be nil.
+func (ix *Index) Search(query string, limit int, boost Boost) []Result {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if boost == nil {
+		boost = func(string) float64 { return 0 }
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return ix.newest(limit, boost)
+	}
+	lower := strings.ToLower(query)
+	queryTerms := ix.queryTokens(query)
+
+	hits := map[string]*hit{}
+	get := func(e *entry) *hit {
+		h := hits[e.doc.ID]
+		if h == nil {
+			h = &hit{entry: e, termScores: make([]float64, len(queryTerms))}
+			hits[e.doc.ID] = h
+		}
+		return h
+	}
+
+	// ID matches: exact, prefix, ticket number and substring
+	numeric := isDigits(lower)
+	for _, e := range ix.docs {
+		score, start, end := idMatch(e, lower, numeric)
+		if score > 0 {
+			h := get(e)
+			h.idScore = score
+			h.highlights = append(h.highlights, Highlight{Field: FieldID, Start: start, End: end})
+		}
+	}
+
+	// Word matches of every query form against the vocabulary, then the
+	// documents containing them
+	for qi, qt := range queryTerms {
+		for _, qf := range qt.forms {
+			for term, ids := range ix.terms {
+				quality := termQuality(qf.text, term)
+				if quality < qualityCompound && ix.norm.SplitCompounds && compoundPart(qf.text, term) {
+					quality = qualityCompound
+				}
+				if quality == 0 {
+					continue
+				}
+				for id := range ids {
+					e := ix.docs[id]
+					h := get(e)
+					for _, t := range e.tokens {
+						f, ok := t.form(term)
+						if !ok {
+							continue
+						}
+						q := quality
+						if qf.stem || f.stem {
+							q *= stemFactor
+						}
+						score := q * fieldWeight[t.field]
+						if score > h.termScores[qi] {
+							h.termScores[qi] = score
+						}
+						h.highlights = append(h.highlights, termHighlight(t, f.text, qf.text))
+					}
+				}
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(hits))
+	for _, h := range hits {
+		score, ok := h.score()
+		if !ok {
+			continue
+		}
+		score += boost(h.entry.doc.ID)
+		results = append(results, Result{ID: h.entry.doc.ID, Score: score, Highlights: mergeHighlights(h.highlights)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return lessByNumberDesc(ix.docs[results[i].ID], ix.docs[results[j].ID])
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// score totals a hit. Without an ID match every query term must have matched.
+func (h *hit) score() (float64, bool) {
+	total := h.idScore
+	for _, s := range h.termScores {
+		if s == 0 && h.idScore == 0 {
+			return 0, false
+		}
+		total += s
+	}
+	return total, total > 0
+}
+
+// newest lists documents by descending boost, then ticket number
+func (ix *Index) newest(limit int, boost Boost) []Result {
+	results := make([]Result, 0, len(ix.docs))
+	for id := range ix.docs {
+		results = append(results, Result{ID: id, Score: boost(id)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return lessByNumberDesc(ix.docs[results[i].ID], ix.docs[results[j].ID])
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// lessByNumberDesc orders newer tickets (higher numbers) first, then by ID
+func lessByNumberDesc(a, b *entry) bool {
+	na, _ := strconv.Atoi(a.number)
+	nb, _ := strconv.Atoi(b.number)
+	if na != nb {
+		return na > nb
+	}
+	return a.doc.ID < b.doc.ID
+}
+
+// idMatch scores query against the ticket ID and returns the matched rune range
+func idMatch(e *entry, lower string, numeric bool) (score float64, start, end int) {
+	idLen := len([]rune(e.idLower))
+	numStart := idLen - len([]rune(e.number))
+	switch {
+	case e.idLower == lower:
+		return scoreIDExact, 0, idLen
+	case numeric && e.number == lower:
+		return scoreIDNumber, numStart, idLen
+	case strings.HasPrefix(e.idLower, lower):
+		return scoreIDPrefix, 0, len([]rune(lower))
+	case numeric && strings.HasPrefix(e.number, lower):
+		return scoreIDNumberPx, numStart, numStart + len(lower)
+	}
+	if i := strings.Index(e.idLower, lower); i >= 0 {
+		s := len([]rune(e.idLower[:i]))
+		return scoreIDContains, s, s + len([]rune(lower))
+	}
+	return 0, 0, 0
+}
+
+// termQuality rates how well the query word q matches the indexed word term;
+// 0 means no match. Short words must match exactly or as a prefix.
+func termQuality(q, term string) float64 {
+	switch {
+	case q == term:
+		return qualityExact
+	case strings.HasPrefix(term, q):
+		return qualityPrefix
+	}
+	qLen, tLen := len([]rune(q)), len([]rune(term))
+	if maxEdits := allowedEdits(qLen); maxEdits > 0 && abs(qLen-tLen) <= maxEdits {
+		if d := editDistance(q, term, maxEdits); d <= maxEdits {
+			return qualityFuzzy - fuzzyPenalty*float64(d-1)
+		}
+	}
+	if qLen >= 3 && strings.Contains(term, q) {
+		return qualitySubstring
+	}
+	return 0
+}
+
+// allowedEdits returns how many typos a query word of n characters tolerates
+func allowedEdits(n int) int {
+	switch {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+// form returns the form of t spelled text
+func (t token) form(text string) (form, bool) {
+	for _, f := range t.forms {
+		if f.text == text {
+			return f, true
+		}
+	}
+	return form{}, false
+}
+
+// termHighlight returns the part of t matched by the query word q through the
+// form text. Forms whose length differs from the original word, e.g. "oe" for
+// "ö", can't be mapped back character by character and highlight all of t.
+func termHighlight(t token, text, q string) Highlight {
+	h := Highlight{Field: t.field, Index: t.index, Start: t.start, End: t.end}
+	if len([]rune(text)) != t.end-t.start {
+		return h
+	}
+	if i := strings.Index(text, q); i >= 0 {
+		h.Start = t.start + len([]rune(text[:i]))
+		h.End = h.Start + len([]rune(q))
+	}
+	return h
+}
+
+// mergeHighlights sorts highlights and joins overlapping ranges
+func mergeHighlights(hs []Highlight) []Highlight {
+	if len(hs) == 0 {
+		return nil
+	}
+	sort.Slice(hs, func(i, j int) bool {
+		a, b := hs[i], hs[j]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		return a.Start < b.Start
+	})
+	merged := []Highlight{hs[0]}
+	for _, h := range hs[1:] {
+		last := &merged[len(merged)-1]
+		if h.Field == last.Field && h.Index == last.Index && h.Start <= last.End {
+			if h.End > last.End {
+				last.End = h.End
+			}
+			continue
+		}
+		merged = append(merged, h)
+	}
+	return merged
+}
+
+// editDistance returns the Damerau-Levenshtein (optimal string alignment)
+// distance between a and b, or max+1 once it is known to exceed max
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
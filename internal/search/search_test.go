@@ -0,0 +1,161 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testDocs = []Document{
+	{ID: "AGV-952", Summary: "Login page crashes on submit", Type: "Bug", Priority: "Critical"},
+	{ID: "AGV-1952", Summary: "Bestandsanpassung für das Lager", Type: "Feature"},
+	{ID: "AGV-95", Summary: "Ablöseangebot erstellen", Type: "Task"},
+	{ID: "JU-17", Summary: "Café menu shows the wrong résumé", Type: "Bug", Sprints: []string{"Sprint 42"}},
+	{ID: "JU-18", Summary: "Updated reports are exported twice", Type: "Bug"},
+	{ID: "JU-19", Summary: "Straße sperren", Type: "Task"},
+}
+
+func newTestIndex(n Normalization) *Index {
+	ix := NewIndex()
+	ix.SetNormalization(n)
+	ix.Upsert(testDocs...)
+	return ix
+}
+
+func resultIDs(results []Result) []string {
+	ids := []string{}
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		norm  *Normalization // nil uses DefaultNormalization
+		query string
+		first string   // expected best hit; "" expects no hits
+		all   []string // if set, the exact result list
+	}{
+		{name: "ticket number", query: "952", first: "AGV-952"},
+		{name: "exact ID", query: "agv-952", first: "AGV-952"},
+		{name: "ID prefix", query: "JU-1", all: []string{"JU-19", "JU-18", "JU-17"}},
+		{name: "summary word", query: "login", all: []string{"AGV-952"}},
+		{name: "summary prefix", query: "crash", first: "AGV-952"},
+		{name: "typo within one edit", query: "lohin", first: "AGV-952"},
+		{name: "two typos in a long word", query: "exportet twise", first: "JU-18"},
+		{name: "too many typos", query: "lxhxn", first: ""},
+		{name: "all words must match", query: "login lager", first: ""},
+		{name: "type", query: "feature", first: "AGV-1952"},
+		{name: "sprint", query: "sprint 42", first: "JU-17"},
+		{name: "umlaut folded", query: "abloese", first: "AGV-95"},
+		{name: "umlaut without dots", query: "ablose", first: "AGV-95"},
+		{name: "umlaut folding off", norm: &Normalization{StripDiacritics: true}, query: "abloese", first: ""},
+		{name: "diacritics stripped", query: "cafe resume", first: "JU-17"},
+		{name: "diacritic stripping off", norm: &Normalization{}, query: "resume", first: ""},
+		{name: "eszett", query: "strasse", first: "JU-19"},
+		{name: "stemming", query: "updating", first: "JU-18"},
+		{name: "stemming off", norm: &Normalization{}, query: "updating", first: ""},
+		{name: "compound part", query: "anpassung", first: "AGV-1952"},
+		{name: "compound head", query: "bestand", first: "AGV-1952"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := DefaultNormalization
+			if tt.norm != nil {
+				n = *tt.norm
+			}
+			got := resultIDs(newTestIndex(n).Search(tt.query, 0, nil))
+			if tt.all != nil {
+				if !reflect.DeepEqual(got, tt.all) {
+					t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.all)
+				}
+				return
+			}
+			if tt.first == "" {
+				if len(got) > 0 {
+					t.Errorf("Search(%q) = %v, want no hits", tt.query, got)
+				}
+				return
+			}
+			if len(got) == 0 || got[0] != tt.first {
+				t.Errorf("Search(%q) = %v, want %s first", tt.query, got, tt.first)
+			}
+		})
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	ix := newTestIndex(DefaultNormalization)
+	tests := []struct {
+		query string
+		want  []Highlight
+	}{
+		{query: "952", want: []Highlight{{Field: FieldID, Start: 4, End: 7}}},
+		{query: "page crash", want: []Highlight{{Field: FieldSummary, Start: 6, End: 10}, {Field: FieldSummary, Start: 11, End: 18}}},
+		{query: "sprint", want: []Highlight{{Field: FieldSprints, Index: 0, Start: 0, End: 6}}},
+		{query: "ablose", want: []Highlight{{Field: FieldSummary, Start: 0, End: 6}}},
+	}
+	for _, tt := range tests {
+		results := ix.Search(tt.query, 1, nil)
+		if len(results) != 1 {
+			t.Errorf("Search(%q) = %v", tt.query, results)
+			continue
+		}
+		if !reflect.DeepEqual(results[0].Highlights, tt.want) {
+			t.Errorf("Search(%q) highlights = %+v, want %+v", tt.query, results[0].Highlights, tt.want)
+		}
+	}
+}
+
+func TestSearchBoost(t *testing.T) {
+	ix := NewIndex()
+	ix.Upsert(
+		Document{ID: "AGV-1", Summary: "Printer driver"},
+		Document{ID: "AGV-2", Summary: "Printer setup"},
+		Document{ID: "AGV-3", Summary: "Scanner"},
+	)
+	boost := func(id string) float64 {
+		if id == "AGV-1" {
+			return 50
+		}
+		return 0
+	}
+
+	// Equal matches are ordered newest first unless boosted
+	if got := resultIDs(ix.Search("printer", 0, nil)); !reflect.DeepEqual(got, []string{"AGV-2", "AGV-1"}) {
+		t.Errorf("unboosted = %v", got)
+	}
+	if got := resultIDs(ix.Search("printer", 0, boost)); !reflect.DeepEqual(got, []string{"AGV-1", "AGV-2"}) {
+		t.Errorf("boosted = %v", got)
+	}
+	// The boost doesn't add matches
+	if got := resultIDs(ix.Search("scanner", 0, boost)); !reflect.DeepEqual(got, []string{"AGV-3"}) {
+		t.Errorf("boosted non-match = %v", got)
+	}
+	// An empty query lists boosted tickets first, then the newest
+	if got := resultIDs(ix.Search("", 0, boost)); !reflect.DeepEqual(got, []string{"AGV-1", "AGV-3", "AGV-2"}) {
+		t.Errorf("empty query = %v", got)
+	}
+	if got := resultIDs(ix.Search("", 2, nil)); !reflect.DeepEqual(got, []string{"AGV-3", "AGV-2"}) {
+		t.Errorf("empty query with limit = %v", got)
+	}
+}
+
+func TestIndexUpsertDelete(t *testing.T) {
+	ix := newTestIndex(DefaultNormalization)
+	ix.Upsert(Document{ID: "AGV-952", Summary: "Logout button missing"})
+	if got := resultIDs(ix.Search("login", 0, nil)); len(got) != 0 {
+		t.Errorf("old summary still found: %v", got)
+	}
+	if got := resultIDs(ix.Search("logout", 0, nil)); !reflect.DeepEqual(got, []string{"AGV-952"}) {
+		t.Errorf("new summary = %v", got)
+	}
+	ix.Delete("AGV-952")
+	if got := resultIDs(ix.Search("logout", 0, nil)); len(got) != 0 {
+		t.Errorf("deleted document found: %v", got)
+	}
+	if ix.Len() != len(testDocs)-1 {
+		t.Errorf("Len = %d", ix.Len())
+	}
+}
@@ -0,0 +1,274 @@
+// Package youtrack is a typed client for the parts of the YouTrack REST API
+// the helper uses: users, projects, issues and agile boards.
+package youtrack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // profile timezones must load on Windows too
+
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+// DefaultPageSize is the $top used by SyncIssues when none is given.
+// YouTrack caps large $top values server-side, so we page with $skip instead.
+const DefaultPageSize = 500
+
+// maxErrorBody limits how much of an error response is kept for logging
+const maxErrorBody = 4096
+
+// Client talks to one YouTrack instance with a permanent token
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// New returns a client for the instance at baseURL. Trailing slashes and
+// surrounding spaces are ignored. httpClient may be nil.
+func New(baseURL, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL: NormalizeBaseURL(baseURL),
+		token:   token,
+		http:    httpClient,
+	}
+}
+
+// NormalizeBaseURL trims spaces and removes a trailing slash so API paths are built correctly
+func NormalizeBaseURL(baseURL string) string {
+	baseURL = strings.TrimSpace(baseURL)
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// BaseURL returns the normalized instance URL
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// get performs an authenticated GET of path with the given query parameters
+// and decodes the JSON response into out. Context cancellation is returned
+// as ctx.Err(); transport failures and non-200 responses as *APIError.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	body, err := c.open(ctx, path, params)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return c.decodeError(ctx, path, err)
+	}
+	return nil
+}
+
+// open performs an authenticated GET of path and returns the body of a 200
+// response for the caller to decode and close. Errors are as for get.
+func (c *Client) open(ctx context.Context, path string, params url.Values) (io.ReadCloser, error) {
+	apiURL := c.baseURL + path
+	if len(params) > 0 {
+		apiURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	logger.Debug("youtrack: GET %s", apiURL)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &APIError{Endpoint: path, Err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		logger.Error("youtrack: status=%d url=%s body=%s", resp.StatusCode, apiURL, string(body))
+		return nil, newStatusError(resp.StatusCode, path, body)
+	}
+	return resp.Body, nil
+}
+
+// decodeError maps a failure while reading a response body
+func (c *Client) decodeError(ctx context.Context, path string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	logger.Error("youtrack: decode error for %s: %v", path, err)
+	return fmt.Errorf("%w: %s: %v", ErrInvalidResponse, path, err)
+}
+
+// ValidateConnection checks that the base URL points at YouTrack and the token is accepted
+func (c *Client) ValidateConnection(ctx context.Context) error {
+	var user User
+	return c.get(ctx, "/api/users/me", url.Values{"fields": {"id"}}, &user)
+}
+
+// GetCurrentUser returns the user the token belongs to
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.get(ctx, "/api/users/me", url.Values{"fields": {"id,login,fullName,name,email"}}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetTimezone returns the timezone of the token user's profile. YouTrack
+// reads dates and times in queries in this zone.
+func (c *Client) GetTimezone(ctx context.Context) (*time.Location, error) {
+	var me struct {
+		Profiles struct {
+			General struct {
+				Timezone struct {
+					ID string `json:"id"`
+				} `json:"timezone"`
+			} `json:"general"`
+		} `json:"profiles"`
+	}
+	if err := c.get(ctx, "/api/users/me", url.Values{"fields": {"profiles(general(timezone(id)))"}}, &me); err != nil {
+		return nil, err
+	}
+	id := me.Profiles.General.Timezone.ID
+	if id == "" {
+		return nil, fmt.Errorf("%w: /api/users/me: no profile timezone", ErrInvalidResponse)
+	}
+	loc, err := time.LoadLocation(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: /api/users/me: %v", ErrInvalidResponse, err)
+	}
+	return loc, nil
+}
+
+// GetProjects returns all projects visible to the token
+func (c *Client) GetProjects(ctx context.Context) ([]Project, error) {
+	var projects []Project
+	if err := c.get(ctx, "/api/admin/projects", url.Values{"fields": {"id,name,shortName,archived"}}, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// GetBoards returns the agile boards visible to the token with their sprints
+func (c *Client) GetBoards(ctx context.Context) ([]Board, error) {
+	var boards []Board
+	params := url.Values{"fields": {"id,name,sprints(id,name,start,finish,archived)"}}
+	if err := c.get(ctx, "/api/agiles", params, &boards); err != nil {
+		return nil, err
+	}
+	return boards, nil
+}
+
+// ListIssues returns one $skip/$top window of the issues matching query
+func (c *Client) ListIssues(ctx context.Context, query string, skip, top int) ([]Issue, error) {
+	var issues []Issue
+	_, err := c.StreamIssues(ctx, query, skip, top, func(issue Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// StreamIssues fetches one $skip/$top window of the issues matching query and
+// calls fn for each issue as soon as it has been decoded, so only one issue
+// is held in memory at a time. It returns how many issues were decoded.
+func (c *Client) StreamIssues(ctx context.Context, query string, skip, top int, fn func(Issue) error) (int, error) {
+	params := url.Values{
+		"query":  {query},
+		"fields": {IssueFields},
+		"$skip":  {strconv.Itoa(skip)},
+		"$top":   {strconv.Itoa(top)},
+	}
+	body, err := c.open(ctx, "/api/issues", params)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	// Tell fn's own errors apart from malformed responses
+	var fnErr error
+	n, err := DecodeIssues(body, func(issue Issue) error {
+		fnErr = fn(issue)
+		return fnErr
+	})
+	if fnErr != nil {
+		return n, fnErr
+	}
+	if err != nil {
+		return n, c.decodeError(ctx, "/api/issues", err)
+	}
+	return n, nil
+}
+
+// SyncIssues pages through every issue matching query, pageSize at a time
+// (DefaultPageSize if <= 0), streaming each issue to fn until YouTrack returns
+// a short page. pageDone, if non-nil, is called after every page with the
+// number of issues it held. It stops with ctx.Err() when ctx is cancelled and
+// with fn's error if fn fails.
+func (c *Client) SyncIssues(ctx context.Context, query string, pageSize int, fn func(Issue) error, pageDone func(n int)) error {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	for skip := 0; ; skip += pageSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := c.StreamIssues(ctx, query, skip, pageSize, fn)
+		if err != nil {
+			return err
+		}
+		if pageDone != nil {
+			pageDone(n)
+		}
+		if n < pageSize {
+			return nil
+		}
+	}
+}
+
+// DecodeIssues reads a JSON array of issues token by token from r and
+// calls fn for each element, stopping at the first error fn returns.
+// It returns the number of issues decoded.
+func DecodeIssues(r io.Reader, fn func(Issue) error) (int, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return 0, fmt.Errorf("expected an array of issues, got %v", tok)
+	}
+
+	n := 0
+	for dec.More() {
+		var issue Issue
+		if err := dec.Decode(&issue); err != nil {
+			return n, err
+		}
+		n++
+		if err := fn(issue); err != nil {
+			return n, err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return n, err
+	}
+	return n, nil
+}
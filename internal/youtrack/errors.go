@@ -0,0 +1,163 @@
+package youtrack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrInvalidURL is wrapped by errors caused by a malformed base URL
+	ErrInvalidURL = errors.New("youtrack: invalid URL")
+	// ErrInvalidResponse is wrapped by errors caused by an undecodable response body
+	ErrInvalidResponse = errors.New("youtrack: invalid response")
+)
+
+// APIError describes a failed API request: either a non-200 answer from
+// YouTrack (Status set) or a transport failure before any answer (Err set).
+// Use errors.As to get at it from the errors returned by Client.
+type APIError struct {
+	Status      int    // HTTP status, 0 if no response was received
+	Code        string // YouTrack "error" field, e.g. "Not Found"
+	Description string // YouTrack "error_description" field
+	Endpoint    string // request path, e.g. /api/issues
+	Body        string // start of the raw response body, for logging
+	Err         error  // transport error, nil when Status is set
+}
+
+func (e *APIError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("youtrack: GET %s: %v", e.Endpoint, e.Err)
+	}
+	msg := fmt.Sprintf("youtrack: %s returned status %d", e.Endpoint, e.Status)
+	switch {
+	case e.Code != "" && e.Description != "":
+		msg += fmt.Sprintf(": %s: %s", e.Code, e.Description)
+	case e.Code != "" || e.Description != "":
+		msg += ": " + e.Code + e.Description
+	}
+	return msg
+}
+
+// Unwrap returns the transport error, if any
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether the failure is caused by a transient condition
+// on the server or network: rate limiting, an overloaded or restarting server,
+// or a timeout.
+func (e *APIError) Temporary() bool {
+	switch e.Status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case 0:
+		var netErr net.Error
+		return errors.As(e.Err, &netErr) && netErr.Timeout()
+	}
+	return false
+}
+
+// Retryable reports whether repeating the same request may succeed. On top
+// of Temporary failures this includes 500s and dropped or refused
+// connections, but not TLS, DNS or malformed request errors.
+func (e *APIError) Retryable() bool {
+	if e.Temporary() || e.Status == http.StatusInternalServerError {
+		return true
+	}
+	if e.Status != 0 || e.Err == nil || e.TLS() {
+		return false
+	}
+	if errors.Is(e.Err, io.EOF) || errors.Is(e.Err, io.ErrUnexpectedEOF) {
+		// Server closed the connection mid-response
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(e.Err, &dnsErr) {
+		return dnsErr.Temporary()
+	}
+	var opErr *net.OpError
+	return errors.As(e.Err, &opErr)
+}
+
+// TLS reports whether the request failed during the TLS handshake or
+// certificate verification
+func (e *APIError) TLS() bool {
+	if e.Err == nil {
+		return false
+	}
+	var (
+		verifyErr   *tls.CertificateVerificationError
+		recordErr   tls.RecordHeaderError
+		alertErr    tls.AlertError
+		unknownAuth x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+	)
+	return errors.As(e.Err, &verifyErr) || errors.As(e.Err, &recordErr) ||
+		errors.As(e.Err, &alertErr) || errors.As(e.Err, &unknownAuth) ||
+		errors.As(e.Err, &hostnameErr) || errors.As(e.Err, &invalidErr)
+}
+
+// Stages of a request, as reported by APIError.Stage
+const (
+	StageProxy    = "proxy"    // connecting to or through the proxy
+	StageDNS      = "dns"      // resolving the host name
+	StageConnect  = "connect"  // opening the TCP connection
+	StageTLS      = "tls"      // TLS handshake or certificate verification
+	StageTimeout  = "timeout"  // the overall request timeout expired
+	StageResponse = "response" // YouTrack answered with an error status
+)
+
+// Stage reports where the request failed: one of the Stage constants, or ""
+// if the transport error can't be attributed
+func (e *APIError) Stage() string {
+	if e.Status != 0 {
+		return StageResponse
+	}
+	if e.Err == nil {
+		return ""
+	}
+	var opErr *net.OpError
+	if errors.As(e.Err, &opErr) && opErr.Op == "proxyconnect" {
+		return StageProxy
+	}
+	// net/http reports handshake timeouts with an unexported error type
+	if e.TLS() || strings.Contains(e.Err.Error(), "TLS handshake") {
+		return StageTLS
+	}
+	var dnsErr *net.DNSError
+	if errors.As(e.Err, &dnsErr) {
+		return StageDNS
+	}
+	if opErr != nil && opErr.Op == "dial" {
+		return StageConnect
+	}
+	var netErr net.Error
+	if errors.As(e.Err, &netErr) && netErr.Timeout() {
+		return StageTimeout
+	}
+	return ""
+}
+
+// newStatusError builds an APIError from a non-200 response, reading the
+// YouTrack error/error_description payload when the body carries one
+func newStatusError(status int, endpoint string, body []byte) *APIError {
+	e := &APIError{Status: status, Endpoint: endpoint, Body: string(body)}
+	var payload struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		e.Code = strings.TrimSpace(payload.Error)
+		e.Description = strings.TrimSpace(payload.Description)
+	}
+	return e
+}
@@ -0,0 +1,73 @@
+package youtrack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// benchIssueCount is the size of the benchmark fixture, roughly a large
+// self-hosted instance synced in one go (~25 MB of JSON)
+const benchIssueCount = 20000
+
+var (
+	benchFixtureOnce sync.Once
+	benchFixture     []byte
+)
+
+// issuesFixture returns a /api/issues response body with benchIssueCount
+// issues, each carrying the custom fields a typical project has
+func issuesFixture(b *testing.B) []byte {
+	benchFixtureOnce.Do(func() {
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i := 0; i < benchIssueCount; i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(&buf, `{"$type":"Issue","id":"2-%d","idReadable":"AGV-%d","summary":"Issue number %d with a reasonably long summary line","created":1700000000000,"updated":1700000500000,"resolved":null,`+
+				`"reporter":{"$type":"User","id":"1-1","login":"jdoe","fullName":"Jane Doe","email":"jane@example.com"},`+
+				`"project":{"$type":"Project","id":"0-1","name":"Agile","shortName":"AGV"},"customFields":[`+
+				`{"$type":"SingleEnumIssueCustomField","name":"Type","value":{"$type":"EnumBundleElement","id":"1","name":"Bug"}},`+
+				`{"$type":"SingleEnumIssueCustomField","name":"Priority","value":{"$type":"EnumBundleElement","id":"2","name":"Major"}},`+
+				`{"$type":"StateIssueCustomField","name":"State","value":{"$type":"StateBundleElement","id":"3","name":"Open","isResolved":false}},`+
+				`{"$type":"SingleUserIssueCustomField","name":"Assignee","value":{"$type":"User","id":"1-2","login":"jsmith","fullName":"John Smith"}},`+
+				`{"$type":"MultiVersionIssueCustomField","name":"Sprints","value":[{"$type":"VersionBundleElement","id":"4","name":"Sprint 41"},{"$type":"VersionBundleElement","id":"5","name":"Sprint 42"}]},`+
+				`{"$type":"PeriodIssueCustomField","name":"Estimation","value":{"$type":"PeriodValue","minutes":480,"presentation":"1d"}},`+
+				`{"$type":"SimpleIssueCustomField","name":"Story points","value":5}]}`, i, i, i)
+		}
+		buf.WriteByte(']')
+		benchFixture = buf.Bytes()
+	})
+	return benchFixture
+}
+
+// BenchmarkDecodeIssuesStream decodes the fixture one issue at a time, as SyncIssues does
+func BenchmarkDecodeIssuesStream(b *testing.B) {
+	data := issuesFixture(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n, err := DecodeIssues(bytes.NewReader(data), func(Issue) error { return nil })
+		if err != nil || n != benchIssueCount {
+			b.Fatalf("decoded %d issues: %v", n, err)
+		}
+	}
+}
+
+// BenchmarkDecodeIssuesSlice decodes the whole fixture into one slice for comparison
+func BenchmarkDecodeIssuesSlice(b *testing.B) {
+	data := issuesFixture(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var issues []Issue
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&issues); err != nil || len(issues) != benchIssueCount {
+			b.Fatalf("decoded %d issues: %v", len(issues), err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package youtrack
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+// Limit defaults used when a LimitTransport setting is zero
+const (
+	DefaultRequestsPerSecond = 10
+	DefaultBurst             = 20
+	DefaultMaxInFlight       = 4
+)
+
+// LimitTransport caps the request rate with a token bucket and the number
+// of requests in flight with a semaphore, so bulk fetches don't flood a
+// shared YouTrack server. A request stays in flight until its response body
+// is closed, since large responses are streamed. Limits can be changed while
+// requests are running.
+type LimitTransport struct {
+	mu       sync.Mutex
+	base     http.RoundTripper // nil means http.DefaultTransport
+	rate     float64           // tokens added per second
+	burst    float64           // bucket size
+	tokens   float64
+	last     time.Time
+	inFlight chan struct{}
+
+	delayed atomic.Int64
+}
+
+// NewLimitTransport wraps base with the default limits
+func NewLimitTransport(base http.RoundTripper) *LimitTransport {
+	t := &LimitTransport{base: base}
+	t.SetLimits(0, 0, 0)
+	return t
+}
+
+// SetLimits changes the rate (requests per second), burst and maximum number
+// of concurrent requests. Zero or negative values select the defaults.
+func (t *LimitTransport) SetLimits(rate float64, burst, maxInFlight int) {
+	if rate <= 0 {
+		rate = DefaultRequestsPerSecond
+	}
+	if burst <= 0 {
+		burst = DefaultBurst
+	}
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultMaxInFlight
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.last.IsZero() {
+		t.tokens = float64(burst)
+		t.last = time.Now()
+	}
+	t.rate = rate
+	t.burst = float64(burst)
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	if t.inFlight == nil || cap(t.inFlight) != maxInFlight {
+		// Requests holding a slot release it to the channel they acquired it from
+		t.inFlight = make(chan struct{}, maxInFlight)
+	}
+}
+
+// SetBase replaces the transport requests are passed on to
+func (t *LimitTransport) SetBase(base http.RoundTripper) {
+	t.mu.Lock()
+	t.base = base
+	t.mu.Unlock()
+}
+
+// Delayed returns how many requests had to wait for the rate limiter or a
+// free in-flight slot
+func (t *LimitTransport) Delayed() int64 {
+	return t.delayed.Load()
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *LimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	t.mu.Lock()
+	base := t.base
+	slots := t.inFlight
+	t.mu.Unlock()
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	waited := false
+	select {
+	case slots <- struct{}{}:
+	default:
+		waited = true
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			t.delayed.Add(1)
+			return nil, ctx.Err()
+		}
+	}
+	release := sync.OnceFunc(func() { <-slots })
+
+	if wait := t.reserve(); wait > 0 {
+		waited = true
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			t.unreserve()
+			release()
+			t.delayed.Add(1)
+			return nil, ctx.Err()
+		}
+	}
+	if waited {
+		n := t.delayed.Add(1)
+		logger.Debug("youtrack: %s %s delayed by client-side limits (%d delayed so far)", req.Method, req.URL.Path, n)
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// slotBody frees the request's in-flight slot when the body is closed
+type slotBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *slotBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// reserve takes one token from the bucket and returns how long the caller
+// must wait before the token becomes valid
+func (t *LimitTransport) reserve() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	t.last = now
+	t.tokens--
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+// unreserve returns a token whose wait was abandoned
+func (t *LimitTransport) unreserve() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens = min(t.tokens+1, t.burst)
+}
@@ -0,0 +1,125 @@
+package youtrack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLimitTransportHoldsSlotUntilBodyClosed streams response bodies the way
+// the issue downloads do and checks that open bodies count as in flight
+func TestLimitTransportHoldsSlotUntilBodyClosed(t *testing.T) {
+	const maxInFlight, requests = 2, 5
+	var (
+		started atomic.Int32
+		done    = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started.Add(1)
+		w.Write([]byte("["))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	lt := NewLimitTransport(nil)
+	lt.SetLimits(1000, 1000, maxInFlight)
+	client := &http.Client{Transport: lt}
+
+	bodies := make(chan *http.Response, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Errorf("GET: %v", err)
+				return
+			}
+			bodies <- resp
+		}()
+	}
+
+	// The first responses are held open; nobody else may reach the server
+	var open []*http.Response
+	for len(open) < maxInFlight {
+		open = append(open, <-bodies)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := started.Load(); n != maxInFlight {
+		t.Fatalf("%d requests reached the server while %d bodies were open, want %d", n, maxInFlight, maxInFlight)
+	}
+
+	// Closing a body lets exactly one more request through
+	open[0].Body.Close()
+	open = append(open[1:], <-bodies)
+	time.Sleep(100 * time.Millisecond)
+	if n := started.Load(); n != maxInFlight+1 {
+		t.Fatalf("%d requests reached the server after one body was closed, want %d", n, maxInFlight+1)
+	}
+
+	for _, resp := range open {
+		resp.Body.Close()
+	}
+	for i := maxInFlight + 1; i < requests; i++ {
+		(<-bodies).Body.Close()
+	}
+	wg.Wait()
+	if n := started.Load(); n != requests {
+		t.Errorf("%d requests reached the server, want %d", n, requests)
+	}
+}
+
+func TestLimitTransportReleasesSlotOnError(t *testing.T) {
+	lt := NewLimitTransport(nil)
+	lt.SetLimits(1000, 1000, 1)
+	client := &http.Client{Transport: lt}
+
+	// Nothing listens on a closed server, so every request fails to connect
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err := client.Do(mustRequest(t, ctx, srv.URL))
+		cancel()
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("request %d: error = %v, want a connection error", i, err)
+		}
+	}
+}
+
+func TestLimitTransportRefundsAbandonedWait(t *testing.T) {
+	lt := NewLimitTransport(nil)
+	lt.SetLimits(1, 1, 4)
+	lt.reserve() // empty the bucket
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := lt.RoundTrip(mustRequest(t, ctx, "http://youtrack.invalid/")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RoundTrip error = %v, want context.DeadlineExceeded", err)
+	}
+	// Without the refund the abandoned request's token would still be owed
+	// and the next one would wait about two seconds
+	if wait := lt.reserve(); wait > time.Second {
+		t.Errorf("next request waits %s, want at most 1s", wait)
+	}
+}
+
+func mustRequest(t *testing.T, ctx context.Context, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -0,0 +1,150 @@
+package youtrack
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+// Retry defaults used by NewRetryTransport
+const (
+	DefaultMaxAttempts   = 4
+	DefaultMinRetryDelay = 500 * time.Millisecond
+	DefaultMaxRetryDelay = 30 * time.Second
+	// maxRetryAfter is the longest Retry-After we are willing to wait for;
+	// longer waits give up and return the 429/503 response
+	maxRetryAfter = 5 * time.Minute
+)
+
+// RetryTransport retries idempotent requests (GET, HEAD) that fail with a
+// network error, 408, 429, 500, 502, 503 or 504. Delays grow exponentially
+// from MinDelay up to MaxDelay with jitter; a Retry-After header on the
+// response takes precedence. Waiting stops as soon as the request context
+// is cancelled, and a wait that would outlast the context's deadline (such as
+// http.Client.Timeout) isn't started: the last response or error is returned
+// instead, so the caller sees the 429 rather than a timeout.
+type RetryTransport struct {
+	Base        http.RoundTripper // nil means http.DefaultTransport
+	MaxAttempts int               // total attempts including the first
+	MinDelay    time.Duration
+	MaxDelay    time.Duration
+}
+
+// NewRetryTransport wraps base with the default retry policy
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Base:        base,
+		MaxAttempts: DefaultMaxAttempts,
+		MinDelay:    DefaultMinRetryDelay,
+		MaxDelay:    DefaultMaxRetryDelay,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !idempotent(req) {
+		return base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if attempt >= t.MaxAttempts || !retryableResponse(resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if after > maxRetryAfter {
+					logger.Warn("youtrack: %s %s: status %d, Retry-After %s too long; giving up", req.Method, req.URL.Path, resp.StatusCode, after)
+					return resp, nil
+				}
+				delay = after
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			logger.Warn("youtrack: %s %s attempt %d/%d: retrying in %s would pass the request deadline; giving up", req.Method, req.URL.Path, attempt, t.MaxAttempts, delay)
+			return resp, err
+		}
+		if resp != nil {
+			logger.Warn("youtrack: %s %s attempt %d/%d: status %d; retrying in %s", req.Method, req.URL.Path, attempt, t.MaxAttempts, resp.StatusCode, delay)
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		} else {
+			logger.Warn("youtrack: %s %s attempt %d/%d: %v; retrying in %s", req.Method, req.URL.Path, attempt, t.MaxAttempts, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before retry number attempt: MinDelay doubled
+// per attempt, capped at MaxDelay, with ±20% jitter
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	d := t.MinDelay
+	for i := 1; i < attempt && d < t.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > t.MaxDelay {
+		d = t.MaxDelay
+	}
+	return time.Duration(float64(d) * (0.8 + 0.4*rand.Float64()))
+}
+
+// idempotent reports whether req can safely be sent again
+func idempotent(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead && req.Method != "" {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+// retryableResponse classifies the outcome of one attempt with the same
+// rules APIError.Retryable applies to the final error
+func retryableResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return (&APIError{Err: err}).Retryable()
+	}
+	return (&APIError{Status: resp.StatusCode}).Retryable()
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,91 @@
+package youtrack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string        // Retry-After of the first, failing response
+		status     int           // status of the first response
+		timeout    time.Duration // http.Client.Timeout
+		wantStatus int           // APIError status; 0 expects success
+		attempts   int32
+		maxElapsed time.Duration
+	}{
+		{name: "retries a 503", status: 503, timeout: 10 * time.Second, attempts: 2, maxElapsed: 5 * time.Second},
+		{name: "honours a short Retry-After", status: 429, retryAfter: "1", timeout: 10 * time.Second, attempts: 2, maxElapsed: 5 * time.Second},
+		{name: "Retry-After past the client timeout returns the 429", status: 429, retryAfter: "3", timeout: time.Second, wantStatus: 429, attempts: 1, maxElapsed: 500 * time.Millisecond},
+		{name: "Retry-After past maxRetryAfter returns the 429", status: 429, retryAfter: "3600", timeout: 10 * time.Second, wantStatus: 429, attempts: 1, maxElapsed: 500 * time.Millisecond},
+		{name: "does not retry a 404", status: 404, timeout: 10 * time.Second, wantStatus: 404, attempts: 1, maxElapsed: 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) == 1 {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte(`{"id":"1-1","login":"jdoe"}`))
+			}))
+			defer srv.Close()
+
+			rt := NewRetryTransport(nil)
+			rt.MinDelay = 10 * time.Millisecond
+			client := New(srv.URL, "token", &http.Client{Transport: rt, Timeout: tt.timeout})
+
+			start := time.Now()
+			_, err := client.GetCurrentUser(context.Background())
+			elapsed := time.Since(start)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("GetCurrentUser: %v", err)
+				}
+			} else {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.Status != tt.wantStatus {
+					t.Fatalf("GetCurrentUser error = %v, want status %d", err, tt.wantStatus)
+				}
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+			if elapsed > tt.maxElapsed {
+				t.Errorf("took %s, want at most %s", elapsed, tt.maxElapsed)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	rt := NewRetryTransport(nil)
+	rt.MinDelay = time.Minute
+	client := New(srv.URL, "token", &http.Client{Transport: rt})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.GetCurrentUser(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetCurrentUser error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation took %s", elapsed)
+	}
+}
@@ -0,0 +1,138 @@
+package youtrack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transport defaults used when a TransportOptions timeout is zero
+const (
+	DefaultDialTimeout    = 10 * time.Second
+	DefaultTLSTimeout     = 10 * time.Second
+	DefaultRequestTimeout = 2 * time.Minute
+)
+
+// ProxyDirect disables proxying, even when HTTP(S)_PROXY is set
+const ProxyDirect = "direct"
+
+// TransportOptions configures the HTTP connection to YouTrack
+type TransportOptions struct {
+	DialTimeout time.Duration // TCP connect
+	TLSTimeout  time.Duration // TLS handshake
+	// Proxy is an http://, https:// or socks5:// proxy URL. Empty uses the
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment; ProxyDirect disables it.
+	Proxy string
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string
+	// CertFile and KeyFile are a PEM client certificate and its key.
+	// KeyFile may be empty when CertFile holds both.
+	CertFile string
+	KeyFile  string
+	// DisableGzip stops asking the server for gzip-compressed responses
+	DisableGzip bool
+}
+
+// TransportError reports which connection setting could not be applied
+type TransportError struct {
+	Setting string // "proxy", "CA bundle" or "client certificate"
+	Err     error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("youtrack: %s: %v", e.Setting, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// NewTransport builds an *http.Transport from opts. Invalid settings are
+// reported as *TransportError.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultDialTimeout
+	}
+	if opts.TLSTimeout <= 0 {
+		opts.TLSTimeout = DefaultTLSTimeout
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{Timeout: opts.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	t.TLSHandshakeTimeout = opts.TLSTimeout
+	t.DisableCompression = opts.DisableGzip
+
+	proxy, err := proxyFunc(opts.Proxy)
+	if err != nil {
+		return nil, &TransportError{Setting: "proxy", Err: err}
+	}
+	t.Proxy = proxy
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CAFile != "" {
+		pool, err := certPool(opts.CAFile)
+		if err != nil {
+			return nil, &TransportError{Setting: "CA bundle", Err: err}
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.CertFile != "" {
+		keyFile := opts.KeyFile
+		if keyFile == "" {
+			keyFile = opts.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, keyFile)
+		if err != nil {
+			return nil, &TransportError{Setting: "client certificate", Err: err}
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// proxyFunc returns the Proxy function for a configured proxy setting
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	proxy = strings.TrimSpace(proxy)
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyDirect:
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in %s", u.Scheme, proxy)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in %s", proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// certPool returns the system roots plus the certificates in the PEM file at path
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,239 @@
+package youtrack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Issue is an issue as returned by /api/issues with IssueFields
+type Issue struct {
+	ID           string        `json:"id"`
+	IDReadable   string        `json:"idReadable"`
+	Summary      string        `json:"summary"`
+	Created      int64         `json:"created"`  // unix millis
+	Updated      int64         `json:"updated"`  // unix millis
+	Resolved     *int64        `json:"resolved"` // unix millis; nil while unresolved
+	Reporter     *User         `json:"reporter"`
+	Project      *Project      `json:"project"`
+	CustomFields []CustomField `json:"customFields"`
+}
+
+// IssueFields is the fields= projection that fills every Issue member
+const IssueFields = "id,idReadable,summary,created,updated,resolved," +
+	"reporter(id,login,fullName,email)," +
+	"project(id,name,shortName)," +
+	"customFields(name,$type,value($type,id,name,login,fullName,presentation,minutes,text,isResolved))"
+
+// User is a YouTrack user
+type User struct {
+	ID       string `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"fullName"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Type     string `json:"$type"`
+}
+
+// DisplayName returns the full name, falling back to name and login
+func (u User) DisplayName() string {
+	switch {
+	case u.FullName != "":
+		return u.FullName
+	case u.Name != "":
+		return u.Name
+	}
+	return u.Login
+}
+
+// Project is a YouTrack project
+type Project struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ShortName string `json:"shortName"`
+	Archived  bool   `json:"archived"`
+}
+
+// Sprint is a sprint of an agile board
+type Sprint struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Start    int64  `json:"start"`  // unix millis
+	Finish   int64  `json:"finish"` // unix millis
+	Archived bool   `json:"archived"`
+}
+
+// Board is an agile board with its sprints
+type Board struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Sprints []Sprint `json:"sprints"`
+}
+
+// BundleElement is a value of an enum-like field: enum, state, version,
+// build, owned and group fields
+type BundleElement struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsResolved bool   `json:"isResolved"` // state fields only
+	Type       string `json:"$type"`
+}
+
+// Period is the value of a period field
+type Period struct {
+	Minutes      int64  `json:"minutes"`
+	Presentation string `json:"presentation"`
+}
+
+// Custom field value kinds, derived from the field's $type
+const (
+	KindEnum   = "enum"
+	KindUser   = "user"
+	KindPeriod = "period"
+	KindDate   = "date"
+	KindSimple = "simple" // integer, float, string or date-time
+	KindText   = "text"
+)
+
+// CustomField is one issue custom field. Its value is decoded according to
+// the field's $type into exactly one of Elements, Users, Period, Number or Text.
+type CustomField struct {
+	Name string
+	Type string // $type, e.g. SingleEnumIssueCustomField
+	Kind string // one of the Kind constants
+	// Multi is set for Multi*IssueCustomField types
+	Multi bool
+
+	Elements []BundleElement
+	Users    []User
+	Period   *Period
+	Number   *float64 // simple numbers and dates (unix millis)
+	Text     *string  // simple strings and text fields
+}
+
+// Empty reports whether the field has no value
+func (f CustomField) Empty() bool {
+	return len(f.Elements) == 0 && len(f.Users) == 0 && f.Period == nil && f.Number == nil && f.Text == nil
+}
+
+// UnmarshalJSON decodes the value based on the field's $type discriminator
+func (f *CustomField) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name  string          `json:"name"`
+		Type  string          `json:"$type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = CustomField{Name: raw.Name, Type: raw.Type, Kind: kindOf(raw.Type), Multi: strings.HasPrefix(raw.Type, "Multi")}
+	if len(raw.Value) == 0 || string(raw.Value) == "null" {
+		return nil
+	}
+	if raw.Type == "" {
+		// Projection without $type: infer the kind from the value shape
+		f.Kind, f.Multi = guessKind(raw.Value)
+	}
+
+	var err error
+	switch f.Kind {
+	case KindUser:
+		f.Users, err = decodeOneOrMany[User](raw.Value)
+	case KindEnum:
+		f.Elements, err = decodeOneOrMany[BundleElement](raw.Value)
+	case KindPeriod:
+		f.Period = &Period{}
+		err = json.Unmarshal(raw.Value, f.Period)
+	case KindText:
+		var text struct {
+			Text string `json:"text"`
+		}
+		if err = json.Unmarshal(raw.Value, &text); err == nil {
+			f.Text = &text.Text
+		}
+	default:
+		err = f.decodeSimple(raw.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("custom field %q (%s): %w", raw.Name, raw.Type, err)
+	}
+	return nil
+}
+
+// decodeSimple handles date and simple fields, whose value is a bare JSON scalar
+func (f *CustomField) decodeSimple(value json.RawMessage) error {
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		f.Number = &v
+	case string:
+		f.Text = &v
+	case bool:
+		s := fmt.Sprint(v)
+		f.Text = &s
+	}
+	return nil
+}
+
+// decodeOneOrMany decodes either a single object or an array of objects
+func decodeOneOrMany[T any](value json.RawMessage) ([]T, error) {
+	if trimmed := strings.TrimSpace(string(value)); strings.HasPrefix(trimmed, "[") {
+		var many []T
+		err := json.Unmarshal(value, &many)
+		return many, err
+	}
+	var one T
+	if err := json.Unmarshal(value, &one); err != nil {
+		return nil, err
+	}
+	return []T{one}, nil
+}
+
+// kindOf maps a custom field $type to its value kind
+func kindOf(fieldType string) string {
+	switch {
+	case strings.Contains(fieldType, "User"):
+		return KindUser
+	case strings.Contains(fieldType, "Period"):
+		return KindPeriod
+	case strings.HasPrefix(fieldType, "Date"):
+		return KindDate
+	case strings.HasPrefix(fieldType, "Text"):
+		return KindText
+	case strings.HasPrefix(fieldType, "Simple"), fieldType == "":
+		return KindSimple
+	}
+	// Enum, State, Version, Build, Owned and Group fields
+	return KindEnum
+}
+
+// guessKind infers the value kind of a custom field whose $type is unknown
+func guessKind(value json.RawMessage) (kind string, multi bool) {
+	trimmed := strings.TrimSpace(string(value))
+	multi = strings.HasPrefix(trimmed, "[")
+	if !multi && !strings.HasPrefix(trimmed, "{") {
+		return KindSimple, false
+	}
+	var probe []map[string]json.RawMessage
+	if multi {
+		_ = json.Unmarshal(value, &probe)
+	} else {
+		var one map[string]json.RawMessage
+		_ = json.Unmarshal(value, &one)
+		probe = append(probe, one)
+	}
+	if len(probe) > 0 {
+		switch {
+		case probe[0]["login"] != nil:
+			return KindUser, multi
+		case probe[0]["minutes"] != nil:
+			return KindPeriod, multi
+		case probe[0]["text"] != nil:
+			return KindText, multi
+		}
+	}
+	return KindEnum, multi
+}
@@ -0,0 +1,267 @@
+// Package youtracktest provides an in-process fake YouTrack server for tests
+// and offline development. It serves /api/users/me, /api/admin/projects and
+// /api/issues from the fixtures in fixtures/ and can inject failures on demand.
+package youtracktest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata"
+)
+
+// Token is the permanent token the fake server accepts
+const Token = "perm:fake-token"
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fault makes matching requests fail instead of being served normally
+type Fault struct {
+	Path       string        // endpoint to fail, e.g. /api/issues; empty matches every endpoint
+	Query      string        // if set, only fail requests whose query parameter contains it
+	Status     int           // status to answer with, e.g. 401, 404, 429 or 500
+	RetryAfter string        // Retry-After header sent with the status, if any
+	Delay      time.Duration // wait before answering; on its own it only slows the response down
+	Malformed  bool          // answer 200 with a truncated JSON body
+	Times      int           // how many requests to fail; 0 means until ClearFaults
+}
+
+// Server is a fake YouTrack instance
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	me       json.RawMessage
+	zone     *time.Location // profile timezone of me; query dates are read in it
+	projects json.RawMessage
+	issues   []json.RawMessage
+	faults   []*Fault
+	requests map[string]int
+}
+
+// NewServer starts a fake server loaded with the default fixtures. Close it when done.
+func NewServer() *Server {
+	s := &Server{requests: map[string]int{}}
+	s.me = mustFixture("users_me.json")
+	var me struct {
+		Profiles struct {
+			General struct {
+				Timezone struct {
+					ID string `json:"id"`
+				} `json:"timezone"`
+			} `json:"general"`
+		} `json:"profiles"`
+	}
+	if err := json.Unmarshal(s.me, &me); err != nil {
+		panic(fmt.Sprintf("youtracktest: users_me fixture: %v", err))
+	}
+	zone, err := time.LoadLocation(me.Profiles.General.Timezone.ID)
+	if err != nil {
+		panic(fmt.Sprintf("youtracktest: users_me fixture: %v", err))
+	}
+	s.zone = zone
+	s.projects = mustFixture("projects.json")
+	var issues []json.RawMessage
+	if err := json.Unmarshal(mustFixture("issues.json"), &issues); err != nil {
+		panic(fmt.Sprintf("youtracktest: issues fixture: %v", err))
+	}
+	s.issues = issues
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func mustFixture(name string) json.RawMessage {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("youtracktest: %v", err))
+	}
+	return data
+}
+
+// SetIssues replaces the served issues. Each issue must at least carry
+// idReadable, updated and project.shortName for queries to match it.
+func (s *Server) SetIssues(issues ...json.RawMessage) {
+	s.mu.Lock()
+	s.issues = issues
+	s.mu.Unlock()
+}
+
+// Inject adds a fault. Faults are matched in the order they were added.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	s.faults = append(s.faults, &f)
+	s.mu.Unlock()
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	s.faults = nil
+	s.mu.Unlock()
+}
+
+// Requests returns how many requests were made to path
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// ServeHTTP implements http.Handler, so the fake can also be mounted on a real listener
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	fault := s.takeFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		switch {
+		case fault.Malformed:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"idReadable":"AGV-1","summary":`))
+			return
+		case fault.Status != 0:
+			if fault.RetryAfter != "" {
+				w.Header().Set("Retry-After", fault.RetryAfter)
+			}
+			writeError(w, fault.Status, http.StatusText(fault.Status), "injected fault")
+			return
+		}
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "Unauthorized", "You are not logged in.")
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/users/me":
+		writeJSON(w, s.me)
+	case "/api/admin/projects":
+		writeJSON(w, s.projects)
+	case "/api/issues":
+		s.serveIssues(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found", "Entity with id "+r.URL.Path+" not found")
+	}
+}
+
+// takeFault returns the first fault matching r and counts it down. Callers hold s.mu.
+func (s *Server) takeFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Path != "" && f.Path != r.URL.Path {
+			continue
+		}
+		if f.Query != "" && !strings.Contains(r.URL.Query().Get("query"), f.Query) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		copied := *f
+		return &copied
+	}
+	return nil
+}
+
+var (
+	projectTerm = regexp.MustCompile(`project:\s*([\w-]+)`)
+	updatedTerm = regexp.MustCompile(`updated:\s*(\S+)\s*\.\.`)
+)
+
+// serveIssues answers /api/issues, honouring the project and updated terms
+// of query together with $skip and $top. Like YouTrack it reads dates in the
+// profile timezone, which is deliberately far west of UTC in the fixture so
+// that clients sending their local time are caught.
+func (s *Server) serveIssues(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := q.Get("query")
+
+	projects := map[string]bool{}
+	for _, m := range projectTerm.FindAllStringSubmatch(query, -1) {
+		projects[m[1]] = true
+	}
+	var since int64
+	if m := updatedTerm.FindStringSubmatch(query); m != nil {
+		t, err := time.ParseInLocation("2006-01-02T15:04:05", m[1], s.zone)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Bad Request", "invalid updated date "+m[1])
+			return
+		}
+		since = t.UnixMilli()
+	}
+	skip, _ := strconv.Atoi(q.Get("$skip"))
+	top, err := strconv.Atoi(q.Get("$top"))
+	if err != nil || top <= 0 {
+		top = 42 // YouTrack's default page size
+	}
+
+	s.mu.Lock()
+	all := s.issues
+	s.mu.Unlock()
+
+	var matched []json.RawMessage
+	for _, raw := range all {
+		var issue struct {
+			Updated int64 `json:"updated"`
+			Project struct {
+				ShortName string `json:"shortName"`
+			} `json:"project"`
+		}
+		if json.Unmarshal(raw, &issue) != nil {
+			continue
+		}
+		if len(projects) > 0 && !projects[issue.Project.ShortName] {
+			continue
+		}
+		if issue.Updated < since {
+			continue
+		}
+		matched = append(matched, raw)
+	}
+
+	if skip > len(matched) {
+		skip = len(matched)
+	}
+	end := skip + top
+	if end > len(matched) {
+		end = len(matched)
+	}
+	page := matched[skip:end]
+	if page == nil {
+		page = []json.RawMessage{}
+	}
+	body, _ := json.Marshal(page)
+	writeJSON(w, body)
+}
+
+func writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// writeError answers with the error payload YouTrack uses
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	body, _ := json.Marshal(map[string]string{"error": code, "error_description": description})
+	w.Write(body)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+const (
+	// defaultSyncInterval is used when Config.SyncIntervalMinutes is unset
+	defaultSyncInterval = 5 * time.Minute
+	// initialSyncDelay gives the UI time to come up before the first sync
+	initialSyncDelay = 5 * time.Second
+	// offlinePollInterval is how often we re-check connectivity while offline
+	offlinePollInterval = 30 * time.Second
+	// minSyncBackoff and maxSyncBackoff bound the retry delay after failed syncs
+	minSyncBackoff = 30 * time.Second
+	maxSyncBackoff = 30 * time.Minute
+	// syncJitter is the maximum fraction a delay is randomly shifted by
+	syncJitter = 0.1
+)
+
+// SyncStatus is the scheduler state reported to the frontend
+type SyncStatus struct {
+	Paused    bool   `json:"paused"`
+	Running   bool   `json:"running"`
+	Offline   bool   `json:"offline"`
+	Failures  int    `json:"failures"`   // consecutive failed syncs
+	LastError string `json:"last_error"` // message of the last failed sync
+	NextSync  int64  `json:"next_sync"`  // unix seconds of the next scheduled sync; 0 if paused
+}
+
+// clock is the scheduler's time source; tests replace it to control the loop
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// syncScheduler runs a sync periodically with jittered intervals and
+// exponential backoff after failures. It skips syncing while the machine is
+// offline and can be paused, resumed and triggered from the UI.
+type syncScheduler struct {
+	run      func() error
+	interval func() time.Duration
+	online   func() bool
+	clock    clock
+	jitter   func(time.Duration) time.Duration
+
+	trigger chan struct{}
+
+	mu     sync.Mutex
+	status SyncStatus
+}
+
+func newSyncScheduler(run func() error, interval func() time.Duration) *syncScheduler {
+	return &syncScheduler{
+		run:      run,
+		interval: interval,
+		online:   networkAvailable,
+		clock:    realClock{},
+		jitter:   withJitter,
+		trigger:  make(chan struct{}, 1),
+	}
+}
+
+// Start runs the scheduler loop until ctx is cancelled
+func (s *syncScheduler) Start(ctx context.Context) {
+	go s.loop(ctx)
+}
+
+func (s *syncScheduler) loop(ctx context.Context) {
+	delay := initialSyncDelay
+	for {
+		s.setNext(delay)
+		timer := s.clock.After(delay)
+		triggered := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer:
+		case <-s.trigger:
+			triggered = true
+		}
+
+		// While paused only an explicit trigger runs a sync
+		if !triggered && s.isPaused() {
+			if !s.waitUntilResumed(ctx) {
+				return
+			}
+		}
+
+		if !s.online() {
+			s.setOffline(true)
+			logger.Debug("sync scheduler: offline, checking again in %s", offlinePollInterval)
+			delay = offlinePollInterval
+			continue
+		}
+		s.setOffline(false)
+
+		s.setRunning(true)
+		err := s.run()
+		s.setRunning(false)
+		if ctx.Err() != nil {
+			return
+		}
+
+		failures := s.recordResult(err)
+		if err != nil {
+			delay = s.jitter(backoffDelay(failures))
+			logger.Warn("sync scheduler: sync failed (%d in a row), retrying in %s: %v", failures, delay.Round(time.Second), err)
+			continue
+		}
+		delay = s.jitter(s.interval())
+		logger.Debug("sync scheduler: next sync in %s", delay.Round(time.Second))
+	}
+}
+
+// waitUntilResumed blocks until Resume or Trigger is called. It returns false if ctx is cancelled first.
+func (s *syncScheduler) waitUntilResumed(ctx context.Context) bool {
+	s.setNext(0)
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.trigger:
+		return true
+	}
+}
+
+// Pause stops periodic syncs until Resume is called
+func (s *syncScheduler) Pause() {
+	s.mu.Lock()
+	s.status.Paused = true
+	s.mu.Unlock()
+	logger.Info("sync scheduler: paused")
+}
+
+// Resume restarts periodic syncs and runs one right away
+func (s *syncScheduler) Resume() {
+	s.mu.Lock()
+	wasPaused := s.status.Paused
+	s.status.Paused = false
+	s.mu.Unlock()
+	if !wasPaused {
+		return
+	}
+	logger.Info("sync scheduler: resumed")
+	s.Trigger()
+}
+
+// Trigger requests an immediate sync, even while paused
+func (s *syncScheduler) Trigger() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Status returns a snapshot of the scheduler state
+func (s *syncScheduler) Status() SyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *syncScheduler) isPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status.Paused
+}
+
+func (s *syncScheduler) setNext(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delay == 0 {
+		s.status.NextSync = 0
+		return
+	}
+	s.status.NextSync = s.clock.Now().Add(delay).Unix()
+}
+
+func (s *syncScheduler) setOffline(offline bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Offline = offline
+}
+
+func (s *syncScheduler) setRunning(running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Running = running
+}
+
+// recordResult updates the failure streak and returns it
+func (s *syncScheduler) recordResult(err error) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.status.Failures++
+		s.status.LastError = err.Error()
+	} else {
+		s.status.Failures = 0
+		s.status.LastError = ""
+	}
+	return s.status.Failures
+}
+
+// backoffDelay doubles minSyncBackoff for every consecutive failure, capped at maxSyncBackoff
+func backoffDelay(failures int) time.Duration {
+	delay := minSyncBackoff
+	for i := 1; i < failures && delay < maxSyncBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxSyncBackoff {
+		delay = maxSyncBackoff
+	}
+	return delay
+}
+
+// withJitter shifts d randomly by up to ±syncJitter so clients don't sync in lockstep
+func withJitter(d time.Duration) time.Duration {
+	spread := float64(d) * syncJitter
+	return d + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// networkAvailable reports whether any non-loopback interface is up with a routable address
+func networkAvailable() bool {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		// Can't tell; let the sync itself fail and back off
+		return true
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: minSyncBackoff},
+		{failures: 1, want: minSyncBackoff},
+		{failures: 2, want: 2 * minSyncBackoff},
+		{failures: 3, want: 4 * minSyncBackoff},
+		{failures: 6, want: 32 * minSyncBackoff},
+		{failures: 7, want: maxSyncBackoff},
+		{failures: 1000, want: maxSyncBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoffDelay(tt.failures); got != tt.want {
+			t.Errorf("backoffDelay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	const d = 10 * time.Minute
+	lo := time.Duration(float64(d) * (1 - syncJitter))
+	hi := time.Duration(float64(d) * (1 + syncJitter))
+	varied := false
+	for i := 0; i < 1000; i++ {
+		got := withJitter(d)
+		if got < lo || got > hi {
+			t.Fatalf("withJitter(%s) = %s, want within [%s, %s]", d, got, lo, hi)
+		}
+		varied = varied || got != d
+	}
+	if !varied {
+		t.Error("withJitter never shifted the delay")
+	}
+}
+
+// fakeClock hands every wait the scheduler starts to the test, which fires
+// it by sending on fire
+type fakeClock struct {
+	now   time.Time
+	waits chan time.Duration
+	fire  chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits <- d
+	return c.fire
+}
+
+// schedulerHarness runs a syncScheduler on a fake clock with a scripted sync function
+type schedulerHarness struct {
+	t      *testing.T
+	s      *syncScheduler
+	clock  *fakeClock
+	runs   chan chan error // each sync waits for its result on the channel sent here
+	online bool
+}
+
+func newSchedulerHarness(t *testing.T) *schedulerHarness {
+	h := &schedulerHarness{
+		t:      t,
+		clock:  &fakeClock{now: time.Unix(1700000000, 0), waits: make(chan time.Duration), fire: make(chan time.Time)},
+		runs:   make(chan chan error),
+		online: true,
+	}
+	h.s = newSyncScheduler(func() error {
+		result := make(chan error)
+		h.runs <- result
+		return <-result
+	}, func() time.Duration { return time.Hour })
+	h.s.clock = h.clock
+	h.s.jitter = func(d time.Duration) time.Duration { return d }
+	h.s.online = func() bool { return h.online }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	h.s.Start(ctx)
+	return h
+}
+
+// expectWait waits for the scheduler to start waiting and checks the delay
+func (h *schedulerHarness) expectWait(want time.Duration) {
+	h.t.Helper()
+	select {
+	case got := <-h.clock.waits:
+		if got != want {
+			h.t.Fatalf("scheduler waits %s, want %s", got, want)
+		}
+	case <-time.After(time.Second):
+		h.t.Fatalf("scheduler didn't start waiting %s", want)
+	}
+	if got, want := h.s.Status().NextSync, h.clock.now.Add(want).Unix(); got != want {
+		h.t.Errorf("NextSync = %d, want %d", got, want)
+	}
+}
+
+func (h *schedulerHarness) fire() {
+	h.t.Helper()
+	select {
+	case h.clock.fire <- h.clock.now:
+	case <-time.After(time.Second):
+		h.t.Fatal("scheduler isn't waiting on the clock")
+	}
+}
+
+// expectRun waits for a sync to start, checks the running status and ends it with err
+func (h *schedulerHarness) expectRun(err error) {
+	h.t.Helper()
+	select {
+	case result := <-h.runs:
+		if !h.s.Status().Running {
+			h.t.Error("status isn't running during a sync")
+		}
+		result <- err
+	case <-time.After(time.Second):
+		h.t.Fatal("no sync started")
+	}
+}
+
+func (h *schedulerHarness) expectNoRun() {
+	h.t.Helper()
+	select {
+	case result := <-h.runs:
+		result <- nil
+		h.t.Fatal("unexpected sync")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSyncSchedulerInterval(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.expectWait(initialSyncDelay)
+	h.fire()
+	h.expectRun(nil)
+	h.expectWait(time.Hour)
+	if st := h.s.Status(); st.Running || st.Failures != 0 || st.LastError != "" {
+		t.Errorf("status after success = %+v", st)
+	}
+	h.fire()
+	h.expectRun(nil)
+	h.expectWait(time.Hour)
+}
+
+func TestSyncSchedulerBackoff(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.expectWait(initialSyncDelay)
+	for i := 1; i <= 3; i++ {
+		h.fire()
+		h.expectRun(errors.New("boom"))
+		h.expectWait(backoffDelay(i))
+		if st := h.s.Status(); st.Failures != i || st.LastError != "boom" {
+			t.Fatalf("status after %d failures = %+v", i, st)
+		}
+	}
+	h.fire()
+	h.expectRun(nil)
+	h.expectWait(time.Hour)
+	if st := h.s.Status(); st.Failures != 0 || st.LastError != "" {
+		t.Errorf("status after recovery = %+v", st)
+	}
+}
+
+func TestSyncSchedulerOffline(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.online = false
+	h.expectWait(initialSyncDelay)
+	h.fire()
+	h.expectWait(offlinePollInterval)
+	if !h.s.Status().Offline {
+		t.Error("status isn't offline")
+	}
+	h.online = true
+	h.fire()
+	h.expectRun(nil)
+	h.expectWait(time.Hour)
+	if h.s.Status().Offline {
+		t.Error("status still offline")
+	}
+}
+
+func TestSyncSchedulerTrigger(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.expectWait(initialSyncDelay)
+	h.s.Trigger()
+	h.expectRun(nil)
+	h.expectWait(time.Hour)
+}
+
+func TestSyncSchedulerPauseResume(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.expectWait(initialSyncDelay)
+	h.s.Pause()
+	h.fire()
+	h.expectNoRun()
+	if st := h.s.Status(); !st.Paused || st.NextSync != 0 {
+		t.Errorf("status while paused = %+v", st)
+	}
+
+	// Trigger syncs once even while paused
+	h.s.Trigger()
+	h.expectRun(nil)
+	h.expectWait(time.Hour)
+	h.fire()
+	h.expectNoRun()
+
+	h.s.Resume()
+	h.expectRun(nil)
+	h.expectWait(time.Hour)
+	if h.s.Status().Paused {
+		t.Error("status still paused after Resume")
+	}
+}
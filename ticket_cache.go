@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zwoabier/youtrack-helper/internal/atomicfile"
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+const (
+	ticketCacheFile = "tickets.json"
+	changeLogFile   = "changes.json"
+	usageFile       = "usage.json"
+	// legacyTicketCacheFile is where older versions kept the cache, relative to the working directory
+	legacyTicketCacheFile = "tickets_cache.json"
+)
+
+// Ticket cache backends selectable with Config.CacheBackend
+const (
+	CacheBackendJSON = "json" // single JSON file; the default
+	CacheBackendBolt = "bolt" // embedded bbolt database with per-ticket upserts
+)
+
+// ticketCacheSchema is the current version of the ticket cache format.
+// Bump it whenever Ticket or its parsing changes in a way old files can't
+// represent, and append a step to ticketCacheMigrations.
+const ticketCacheSchema = 3
+
+// errCacheInvalidated is returned for cache data that can't be used, like a
+// file written by a newer version; the cache is discarded and the next sync
+// is a full resync.
+var errCacheInvalidated = errors.New("ticket cache invalidated by schema migration")
+
+// ticketCacheMeta describes which sync the cached tickets come from
+type ticketCacheMeta struct {
+	BaseURL  string   `json:"base_url"`
+	Projects []string `json:"projects"`
+	SyncedAt int64    `json:"synced_at"` // unix seconds of the sync the tickets come from
+}
+
+// ticketCache persists the tickets of one instance
+type ticketCache interface {
+	// Load passes the cached tickets to fn, possibly in several batches, and
+	// returns the cache metadata. A cache that doesn't exist yet loads empty.
+	Load(fn func([]Ticket)) (ticketCacheMeta, error)
+	// Save persists the store state. snapshot is the complete ticket set and
+	// change lists what differs from the previously saved state.
+	Save(meta ticketCacheMeta, snapshot []Ticket, change StoreChange) error
+	Close() error
+}
+
+// openInstance points the ticket cache and change log at the directory of the
+// configured instance and loads both. Without a base URL nothing is persisted.
+func (a *App) openInstance() {
+	a.cacheMu.Lock()
+	defer a.cacheMu.Unlock()
+	if a.cache != nil {
+		if err := a.cache.Close(); err != nil {
+			logger.Warn("closing ticket cache: %v", err)
+		}
+		a.cache = nil
+	}
+	a.instanceDir = ""
+	cfg := a.currentConfig()
+	if cfg.BaseURL != "" {
+		dir, err := a.cm.InstanceDir(cfg.BaseURL)
+		if err != nil {
+			logger.Error("creating instance cache dir: %v", err)
+		} else {
+			a.instanceDir = dir
+			migrateLegacyTicketCache(cfg.BaseURL, filepath.Join(dir, ticketCacheFile))
+			a.cache = openTicketCache(cfg.CacheBackend, dir)
+		}
+	}
+
+	a.store.Replace(nil)
+	a.fullSyncRequired = false
+	if err := a.loadTicketsFromCache(); err != nil {
+		logger.Warn("loading tickets from cache: %v", err)
+	} else {
+		logger.Debug("loaded %d cached tickets", a.store.Len())
+	}
+
+	changesPath := ""
+	if a.instanceDir != "" {
+		changesPath = filepath.Join(a.instanceDir, changeLogFile)
+	}
+	if err := a.changes.Open(changesPath); err != nil {
+		logger.Warn("loading change log: %v", err)
+	}
+
+	usagePath := ""
+	if a.instanceDir != "" {
+		usagePath = filepath.Join(a.instanceDir, usageFile)
+	}
+	if err := a.usage.Open(usagePath); err != nil {
+		logger.Warn("loading usage history: %v", err)
+	}
+}
+
+// openTicketCache opens the configured cache backend in dir, falling back to
+// the JSON file if the database can't be opened.
+func openTicketCache(backend, dir string) ticketCache {
+	jsonCache := &jsonTicketCache{path: filepath.Join(dir, ticketCacheFile)}
+	if backend != CacheBackendBolt {
+		return jsonCache
+	}
+	db, err := openBoltTicketCache(filepath.Join(dir, boltTicketCacheFile))
+	if err != nil {
+		logger.Error("opening ticket database, using JSON cache instead: %v", err)
+		return jsonCache
+	}
+	if err := db.importFrom(jsonCache); err != nil {
+		logger.Warn("importing JSON ticket cache into database: %v", err)
+	}
+	return db
+}
+
+// loadTicketsFromCache streams tickets from the instance cache into the store.
+// It requests a full resync when the cache had to be invalidated or doesn't
+// match the configured instance, projects or last sync.
+func (a *App) loadTicketsFromCache() error {
+	if a.cache == nil {
+		return nil
+	}
+	meta, err := a.cache.Load(func(batch []Ticket) {
+		a.store.Upsert(batch...)
+	})
+	if err != nil {
+		a.fullSyncRequired = true
+		return err
+	}
+	if a.store.Len() == 0 {
+		return nil
+	}
+
+	cfg := a.currentConfig()
+	switch {
+	case normalizeBaseURL(meta.BaseURL) != normalizeBaseURL(cfg.BaseURL):
+		logger.Info("ticket cache was synced from a different base URL; full resync required")
+		a.fullSyncRequired = true
+	case !sameProjectSet(meta.Projects, cfg.Projects):
+		logger.Info("ticket cache was synced for different projects; full resync required")
+		a.fullSyncRequired = true
+	case meta.SyncedAt < cfg.LastSyncTime:
+		logger.Info("ticket cache is older than the last sync; full resync required")
+		a.fullSyncRequired = true
+	}
+	return nil
+}
+
+// saveTicketsToCache persists the store after a sync that made change
+func (a *App) saveTicketsToCache(change StoreChange) error {
+	if a.cache == nil {
+		return nil
+	}
+	cfg := a.currentConfig()
+	meta := ticketCacheMeta{
+		BaseURL:  normalizeBaseURL(cfg.BaseURL),
+		Projects: cfg.Projects,
+		SyncedAt: cfg.LastSyncTime,
+	}
+	return a.cache.Save(meta, a.store.Snapshot(), change)
+}
+
+// sameProjectSet reports whether a and b contain the same project short names in any order
+func sameProjectSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, p := range a {
+		seen[p]++
+	}
+	for _, p := range b {
+		if seen[p] == 0 {
+			return false
+		}
+		seen[p]--
+	}
+	return true
+}
+
+// jsonTicketCache keeps the whole cache in one indented JSON file
+type jsonTicketCache struct {
+	path string
+}
+
+// ticketCacheEnvelope is the on-disk format of jsonTicketCache
+type ticketCacheEnvelope struct {
+	SchemaVersion int `json:"schema_version"`
+	ticketCacheMeta
+	Tickets []Ticket `json:"tickets"`
+}
+
+// ticketCacheMigrations[v] upgrades a version v cache file to version v+1.
+// Tickets are kept so search works right away; steps that can't fill in what
+// the old format lacked clear the metadata, which forces a full resync.
+var ticketCacheMigrations = []func(raw []byte) ([]byte, error){
+	// 0 -> 1: bare JSON array of tickets -> envelope. The sync scope of the
+	// tickets is unknown, so the metadata stays empty and forces a full resync.
+	func(raw []byte) ([]byte, error) {
+		var tickets []Ticket
+		if err := json.Unmarshal(raw, &tickets); err != nil {
+			return nil, err
+		}
+		return json.Marshal(ticketCacheEnvelope{SchemaVersion: 1, Tickets: tickets})
+	},
+	// 1 -> 2: custom fields are parsed generically into Ticket.CustomFields
+	// and through the field mapping; old tickets lack them.
+	resyncMigration(2),
+	// 2 -> 3: tickets gained state, people and timestamps
+	resyncMigration(3),
+}
+
+// resyncMigration returns a step to version that keeps the tickets as they
+// are and clears the metadata
+func resyncMigration(version int) func(raw []byte) ([]byte, error) {
+	return func(raw []byte) ([]byte, error) {
+		var env ticketCacheEnvelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return nil, err
+		}
+		return json.Marshal(ticketCacheEnvelope{SchemaVersion: version, Tickets: env.Tickets})
+	}
+}
+
+// decodeTicketCache parses a cache file of any known schema version,
+// migrating it to the current one.
+func decodeTicketCache(raw []byte) (ticketCacheEnvelope, error) {
+	var env ticketCacheEnvelope
+	version := 0
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || trimmed[0] != '[' {
+		var header struct {
+			SchemaVersion int `json:"schema_version"`
+		}
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return env, err
+		}
+		version = header.SchemaVersion
+	}
+	if version > ticketCacheSchema {
+		return env, fmt.Errorf("%w: schema version %d is newer than %d", errCacheInvalidated, version, ticketCacheSchema)
+	}
+
+	for ; version < ticketCacheSchema; version++ {
+		migrated, err := ticketCacheMigrations[version](raw)
+		if err != nil {
+			return env, fmt.Errorf("migrating ticket cache from schema %d: %w", version, err)
+		}
+		logger.Info("migrated ticket cache from schema %d to %d", version, version+1)
+		raw = migrated
+	}
+
+	err := json.Unmarshal(raw, &env)
+	return env, err
+}
+
+func (c *jsonTicketCache) Load(fn func([]Ticket)) (ticketCacheMeta, error) {
+	var env ticketCacheEnvelope
+	var decodeErr error
+	_, err := atomicfile.Read(c.path, func(data []byte) error {
+		env, decodeErr = decodeTicketCache(data)
+		if errors.Is(decodeErr, errCacheInvalidated) {
+			// Well-formed but unusable; don't fall back to the backup
+			return nil
+		}
+		return decodeErr
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return ticketCacheMeta{}, nil
+	}
+	if err != nil {
+		return ticketCacheMeta{}, fmt.Errorf("failed to read %s: %w", c.path, err)
+	}
+	if decodeErr != nil {
+		return ticketCacheMeta{}, decodeErr
+	}
+	fn(env.Tickets)
+	return env.ticketCacheMeta, nil
+}
+
+func (c *jsonTicketCache) Save(meta ticketCacheMeta, snapshot []Ticket, change StoreChange) error {
+	data, err := json.MarshalIndent(ticketCacheEnvelope{
+		SchemaVersion:   ticketCacheSchema,
+		ticketCacheMeta: meta,
+		Tickets:         snapshot,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tickets: %w", err)
+	}
+	return atomicfile.Write(c.path, data, 0600)
+}
+
+func (c *jsonTicketCache) Close() error {
+	return nil
+}
+
+// migrateLegacyTicketCache moves a tickets_cache.json from the working
+// directory to dest, unless dest already exists or the legacy tickets were
+// synced from a different instance.
+func migrateLegacyTicketCache(baseURL, dest string) {
+	if _, err := os.Stat(dest); err == nil {
+		return
+	}
+	data, err := os.ReadFile(legacyTicketCacheFile)
+	if err != nil {
+		return
+	}
+
+	var tickets []Ticket
+	if err := json.Unmarshal(data, &tickets); err != nil {
+		logger.Warn("legacy ticket cache is unreadable; not migrating: %v", err)
+		return
+	}
+	prefix := normalizeBaseURL(baseURL) + "/"
+	for _, t := range tickets {
+		if !strings.HasPrefix(t.Url, prefix) {
+			logger.Info("legacy ticket cache belongs to another instance; not migrating")
+			return
+		}
+	}
+
+	if err := os.Rename(legacyTicketCacheFile, dest); err != nil {
+		// Rename fails across filesystems; fall back to copy and remove
+		if err := copyFile(legacyTicketCacheFile, dest); err != nil {
+			logger.Warn("migrating legacy ticket cache: %v", err)
+			return
+		}
+		_ = os.Remove(legacyTicketCacheFile)
+	}
+	logger.Info("migrated %d tickets from %s to %s", len(tickets), legacyTicketCacheFile, dest)
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+const (
+	boltTicketCacheFile = "tickets.db"
+	// boltLoadBatch is how many tickets Load hands to the store at a time
+	boltLoadBatch = 1000
+)
+
+var (
+	boltMetaBucket    = []byte("meta")
+	boltTicketsBucket = []byte("tickets") // ticket ID -> JSON Ticket
+	boltMetaKey       = []byte("meta")    // JSON ticketCacheMeta
+	boltSchemaKey     = []byte("schema")  // decimal ticketCacheSchema
+)
+
+// Secondary indexes; keys are "<value>\x00<ticket ID>" with empty values
+var boltIndexes = []struct {
+	field  string
+	bucket []byte
+	values func(Ticket) []string
+}{
+	{"project", []byte("idx_project"), func(t Ticket) []string { return []string{t.Project()} }},
+	{"type", []byte("idx_type"), func(t Ticket) []string { return []string{t.Type} }},
+	{"priority", []byte("idx_priority"), func(t Ticket) []string { return []string{t.Priority} }},
+	{"sprint", []byte("idx_sprint"), func(t Ticket) []string { return t.Sprints }},
+}
+
+// boltTicketCache stores one ticket per key in an embedded bbolt database so
+// syncs only write what changed, and keeps indexes for field lookups.
+type boltTicketCache struct {
+	db *bolt.DB
+}
+
+func openBoltTicketCache(path string) (*boltTicketCache, error) {
+	// A second app instance holds the file lock; don't hang waiting for it
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	c := &boltTicketCache{db: db}
+	if err := c.ensureSchema(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// ensureSchema creates the buckets and wipes the database if it was written
+// with another schema version; the next sync is then a full resync.
+func (c *boltTicketCache) ensureSchema() error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		if meta := tx.Bucket(boltMetaBucket); meta != nil {
+			version, _ := strconv.Atoi(string(meta.Get(boltSchemaKey)))
+			if version != ticketCacheSchema {
+				logger.Info("ticket database schema %d != %d; discarding it", version, ticketCacheSchema)
+				if err := c.dropAll(tx); err != nil {
+					return err
+				}
+			}
+		}
+		meta, err := tx.CreateBucketIfNotExists(boltMetaBucket)
+		if err != nil {
+			return err
+		}
+		if err := meta.Put(boltSchemaKey, []byte(strconv.Itoa(ticketCacheSchema))); err != nil {
+			return err
+		}
+		return c.createDataBuckets(tx)
+	})
+}
+
+func (c *boltTicketCache) createDataBuckets(tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(boltTicketsBucket); err != nil {
+		return err
+	}
+	for _, idx := range boltIndexes {
+		if _, err := tx.CreateBucketIfNotExists(idx.bucket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropAll deletes every bucket
+func (c *boltTicketCache) dropAll(tx *bolt.Tx) error {
+	var names [][]byte
+	if err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		names = append(names, append([]byte(nil), name...))
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importFrom seeds an empty database from the JSON cache, e.g. after switching backends
+func (c *boltTicketCache) importFrom(src *jsonTicketCache) error {
+	empty := true
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		empty = tx.Bucket(boltTicketsBucket).Stats().KeyN == 0
+		return nil
+	}); err != nil || !empty {
+		return err
+	}
+
+	var tickets []Ticket
+	meta, err := src.Load(func(batch []Ticket) {
+		tickets = append(tickets, batch...)
+	})
+	if err != nil || len(tickets) == 0 {
+		return err
+	}
+	change := StoreChange{}
+	for _, t := range tickets {
+		change.Added = append(change.Added, t.ID)
+	}
+	logger.Info("importing %d tickets from JSON cache into ticket database", len(tickets))
+	return c.Save(meta, tickets, change)
+}
+
+func (c *boltTicketCache) Load(fn func([]Ticket)) (ticketCacheMeta, error) {
+	var meta ticketCacheMeta
+	err := c.db.View(func(tx *bolt.Tx) error {
+		if raw := tx.Bucket(boltMetaBucket).Get(boltMetaKey); raw != nil {
+			if err := json.Unmarshal(raw, &meta); err != nil {
+				return fmt.Errorf("decoding ticket database metadata: %w", err)
+			}
+		}
+		batch := make([]Ticket, 0, boltLoadBatch)
+		err := tx.Bucket(boltTicketsBucket).ForEach(func(k, v []byte) error {
+			var t Ticket
+			if err := json.Unmarshal(v, &t); err != nil {
+				return fmt.Errorf("decoding ticket %s: %w", k, err)
+			}
+			batch = append(batch, t)
+			if len(batch) == boltLoadBatch {
+				fn(batch)
+				batch = make([]Ticket, 0, boltLoadBatch)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			fn(batch)
+		}
+		return nil
+	})
+	return meta, err
+}
+
+func (c *boltTicketCache) Save(meta ticketCacheMeta, snapshot []Ticket, change StoreChange) error {
+	changed := make(map[string]bool, len(change.Added)+len(change.Updated))
+	for _, id := range change.Added {
+		changed[id] = true
+	}
+	for _, id := range change.Updated {
+		changed[id] = true
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		tickets := tx.Bucket(boltTicketsBucket)
+		for _, id := range change.Removed {
+			if err := c.remove(tx, tickets, id); err != nil {
+				return err
+			}
+		}
+		for _, t := range snapshot {
+			if !changed[t.ID] {
+				continue
+			}
+			if err := c.remove(tx, tickets, t.ID); err != nil {
+				return err
+			}
+			if err := c.put(tx, tickets, t); err != nil {
+				return err
+			}
+		}
+
+		raw, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(boltMetaBucket).Put(boltMetaKey, raw)
+	})
+}
+
+// put writes a ticket and its index entries
+func (c *boltTicketCache) put(tx *bolt.Tx, tickets *bolt.Bucket, t Ticket) error {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := tickets.Put([]byte(t.ID), raw); err != nil {
+		return err
+	}
+	for _, idx := range boltIndexes {
+		b := tx.Bucket(idx.bucket)
+		for _, v := range idx.values(t) {
+			if err := b.Put(indexKey(v, t.ID), nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// remove deletes a ticket and its index entries; unknown IDs are ignored
+func (c *boltTicketCache) remove(tx *bolt.Tx, tickets *bolt.Bucket, id string) error {
+	raw := tickets.Get([]byte(id))
+	if raw == nil {
+		return nil
+	}
+	var old Ticket
+	if err := json.Unmarshal(raw, &old); err == nil {
+		for _, idx := range boltIndexes {
+			b := tx.Bucket(idx.bucket)
+			for _, v := range idx.values(old) {
+				if err := b.Delete(indexKey(v, id)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return tickets.Delete([]byte(id))
+}
+
+// Query returns the tickets whose field ("project", "type", "priority" or
+// "sprint") equals value, using the secondary indexes.
+func (c *boltTicketCache) Query(field, value string) ([]Ticket, error) {
+	var bucket []byte
+	for _, idx := range boltIndexes {
+		if idx.field == field {
+			bucket = idx.bucket
+		}
+	}
+	if bucket == nil {
+		return nil, fmt.Errorf("no index on field %q", field)
+	}
+
+	result := []Ticket{}
+	err := c.db.View(func(tx *bolt.Tx) error {
+		tickets := tx.Bucket(boltTicketsBucket)
+		prefix := indexKey(value, "")
+		cur := tx.Bucket(bucket).Cursor()
+		for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+			raw := tickets.Get(k[len(prefix):])
+			if raw == nil {
+				continue
+			}
+			var t Ticket
+			if err := json.Unmarshal(raw, &t); err != nil {
+				return err
+			}
+			result = append(result, t)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Page returns up to limit tickets in ID order, starting after the ticket ID
+// after ("" starts at the first). next is the ID to pass as after for the
+// following page, or "" if there is none. Only the page is read from disk.
+func (c *boltTicketCache) Page(after string, limit int) (tickets []Ticket, next string, err error) {
+	tickets = []Ticket{}
+	err = c.db.View(func(tx *bolt.Tx) error {
+		cur := tx.Bucket(boltTicketsBucket).Cursor()
+		k, v := cur.Seek([]byte(after))
+		if k != nil && after != "" && string(k) == after {
+			k, v = cur.Next()
+		}
+		for ; k != nil; k, v = cur.Next() {
+			if len(tickets) == limit {
+				next = tickets[len(tickets)-1].ID
+				return nil
+			}
+			var t Ticket
+			if err := json.Unmarshal(v, &t); err != nil {
+				return fmt.Errorf("decoding ticket %s: %w", k, err)
+			}
+			tickets = append(tickets, t)
+		}
+		return nil
+	})
+	return tickets, next, err
+}
+
+func (c *boltTicketCache) Close() error {
+	if c.db == nil {
+		return errors.New("ticket database already closed")
+	}
+	err := c.db.Close()
+	c.db = nil
+	return err
+}
+
+func indexKey(value, id string) []byte {
+	return []byte(value + "\x00" + id)
+}
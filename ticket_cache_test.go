@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/zwoabier/youtrack-helper/internal/youtracktest"
+)
+
+func TestDecodeTicketCache(t *testing.T) {
+	tickets := `[{"id":"AGV-1","summary":"One"},{"id":"AGV-2","summary":"Two"}]`
+	meta := `"base_url":"https://yt.example.com","projects":["AGV"],"synced_at":1700000000`
+	tests := []struct {
+		name     string
+		raw      string
+		wantMeta ticketCacheMeta
+		wantErr  error
+	}{
+		{name: "schema 0 array", raw: tickets},
+		{name: "schema 1", raw: `{"schema_version":1,` + meta + `,"tickets":` + tickets + `}`},
+		{name: "schema 2", raw: `{"schema_version":2,` + meta + `,"tickets":` + tickets + `}`},
+		{
+			name:     "current schema keeps its metadata",
+			raw:      fmt.Sprintf(`{"schema_version":%d,%s,"tickets":%s}`, ticketCacheSchema, meta, tickets),
+			wantMeta: ticketCacheMeta{BaseURL: "https://yt.example.com", Projects: []string{"AGV"}, SyncedAt: 1700000000},
+		},
+		{
+			name:    "newer schema",
+			raw:     fmt.Sprintf(`{"schema_version":%d,"tickets":%s}`, ticketCacheSchema+1, tickets),
+			wantErr: errCacheInvalidated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := decodeTicketCache([]byte(tt.raw))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("decodeTicketCache error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Migrated tickets survive; their metadata is cleared so a full resync follows
+			if got := ticketIDs(env.Tickets); !reflect.DeepEqual(got, []string{"AGV-1", "AGV-2"}) {
+				t.Errorf("tickets = %v", got)
+			}
+			if !reflect.DeepEqual(env.ticketCacheMeta, tt.wantMeta) {
+				t.Errorf("meta = %+v, want %+v", env.ticketCacheMeta, tt.wantMeta)
+			}
+		})
+	}
+}
+
+func TestLegacyTicketCacheMigration(t *testing.T) {
+	srv := youtracktest.NewServer()
+	defer srv.Close()
+	t.Chdir(t.TempDir())
+	legacy := fmt.Sprintf(`[{"id":"AGV-951","summary":"Old summary","url":"%[1]s/issue/AGV-951"},`+
+		`{"id":"AGV-999","summary":"Deleted since","url":"%[1]s/issue/AGV-999"}]`, srv.URL)
+	if err := os.WriteFile(legacyTicketCacheFile, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTestApp(t, srv, Config{Projects: []string{"AGV", "JU"}})
+	if got := ticketIDs(a.store.Snapshot()); !reflect.DeepEqual(got, []string{"AGV-951", "AGV-999"}) {
+		t.Fatalf("store after migration = %v", got)
+	}
+	if !a.fullSyncRequired {
+		t.Error("migrated cache doesn't require a full resync")
+	}
+	if _, err := os.Stat(legacyTicketCacheFile); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("legacy cache still in the working directory: %v", err)
+	}
+
+	if _, err := a.SyncTickets(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ticketIDs(a.store.Snapshot()); !reflect.DeepEqual(got, []string{"AGV-951", "AGV-952", "AGV-953", "JU-17", "JU-18"}) {
+		t.Errorf("store after the first sync = %v", got)
+	}
+	if tk, _ := a.store.Get("AGV-951"); tk.Summary == "Old summary" {
+		t.Error("the first sync after the migration kept the legacy ticket")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zwoabier/youtrack-helper/internal/atomicfile"
+)
+
+const (
+	// maxChangeLogEntries caps the on-disk change log
+	maxChangeLogEntries = 5000
+	// changeLogRetention drops entries older than this on every append
+	changeLogRetention = 30 * 24 * time.Hour
+)
+
+// Change kinds recorded in the change log
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeUpdated = "updated"
+)
+
+// FieldChange records the old and new value of one ticket field
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// TicketChange is one entry in the change log
+type TicketChange struct {
+	Time     int64         `json:"time"` // unix seconds of the sync that saw the change
+	TicketID string        `json:"ticket_id"`
+	Kind     string        `json:"kind"` // ChangeAdded, ChangeRemoved or ChangeUpdated
+	Summary  string        `json:"summary"`
+	Fields   []FieldChange `json:"fields,omitempty"` // only for ChangeUpdated
+}
+
+// trackedFields are the ticket fields compared between syncs
+var trackedFields = []struct {
+	name string
+	get  func(Ticket) string
+}{
+	{"summary", func(t Ticket) string { return t.Summary }},
+	{"type", func(t Ticket) string { return t.Type }},
+	{"priority", func(t Ticket) string { return t.Priority }},
+	{"sprints", func(t Ticket) string { return strings.Join(t.Sprints, ", ") }},
+	{"state", func(t Ticket) string { return t.State }},
+	{"assignee", func(t Ticket) string { return t.Assignee.FullName }},
+}
+
+// diffTickets compares two cache snapshots and returns one change per ticket
+// that appeared, vanished or had a tracked field modified, ordered by ticket ID.
+func diffTickets(prev, next []Ticket, at time.Time) []TicketChange {
+	old := make(map[string]Ticket, len(prev))
+	for _, t := range prev {
+		old[t.ID] = t
+	}
+
+	var changes []TicketChange
+	seen := make(map[string]bool, len(next))
+	for _, t := range next {
+		seen[t.ID] = true
+		before, ok := old[t.ID]
+		if !ok {
+			changes = append(changes, TicketChange{Time: at.Unix(), TicketID: t.ID, Kind: ChangeAdded, Summary: t.Summary})
+			continue
+		}
+		var fields []FieldChange
+		for _, f := range trackedFields {
+			if o, n := f.get(before), f.get(t); o != n {
+				fields = append(fields, FieldChange{Field: f.name, Old: o, New: n})
+			}
+		}
+		if len(fields) > 0 {
+			changes = append(changes, TicketChange{Time: at.Unix(), TicketID: t.ID, Kind: ChangeUpdated, Summary: t.Summary, Fields: fields})
+		}
+	}
+	for _, t := range prev {
+		if !seen[t.ID] {
+			changes = append(changes, TicketChange{Time: at.Unix(), TicketID: t.ID, Kind: ChangeRemoved, Summary: t.Summary})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].TicketID < changes[j].TicketID })
+	return changes
+}
+
+// changeLog is a rolling, size- and age-limited log of ticket changes persisted as JSON
+type changeLog struct {
+	mu      sync.Mutex
+	path    string
+	entries []TicketChange // oldest first
+}
+
+// Open switches the log to the file at path and reads it; a missing file is an
+// empty log. An empty path disables persistence.
+func (l *changeLog) Open(path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.path = path
+	l.entries = nil
+	if path == "" {
+		return nil
+	}
+	var entries []TicketChange
+	_, err := atomicfile.Read(l.path, func(data []byte) error {
+		entries = nil
+		return json.Unmarshal(data, &entries)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read change log: %w", err)
+	}
+	l.entries = entries
+	return nil
+}
+
+// Append adds changes, trims the log and writes it to disk
+func (l *changeLog) Append(changes []TicketChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, changes...)
+	cutoff := time.Now().Add(-changeLogRetention).Unix()
+	start := 0
+	for start < len(l.entries) && l.entries[start].Time < cutoff {
+		start++
+	}
+	if len(l.entries)-start > maxChangeLogEntries {
+		start = len(l.entries) - maxChangeLogEntries
+	}
+	l.entries = append([]TicketChange(nil), l.entries[start:]...)
+
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal change log: %w", err)
+	}
+	return atomicfile.Write(l.path, data, 0600)
+}
+
+// Since returns the changes recorded at or after since (unix seconds), newest first
+func (l *changeLog) Since(since int64) []TicketChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := []TicketChange{}
+	for i := len(l.entries) - 1; i >= 0 && l.entries[i].Time >= since; i-- {
+		result = append(result, l.entries[i])
+	}
+	return result
+}
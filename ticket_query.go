@@ -0,0 +1,158 @@
+package main
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/zwoabier/youtrack-helper/internal/query"
+)
+
+// queryField is a built-in ticket field usable in search queries
+type queryField struct {
+	kind   query.Kind
+	values func(Ticket) []string
+	number func(Ticket) int64 // date fields, unix millis; 0 means empty
+}
+
+func personValues(p Person) []string {
+	return []string{p.Login, p.FullName}
+}
+
+// queryFields maps the lower-cased YouTrack field names and their usual
+// aliases to ticket attributes
+var queryFields = map[string]queryField{
+	"project":  {kind: query.KindEnum, values: func(t Ticket) []string { return []string{t.Project()} }},
+	"summary":  {kind: query.KindText, values: func(t Ticket) []string { return []string{t.Summary} }},
+	"type":     {kind: query.KindEnum, values: func(t Ticket) []string { return []string{t.Type} }},
+	"priority": {kind: query.KindEnum, values: func(t Ticket) []string { return []string{t.Priority} }},
+	"state":    {kind: query.KindEnum, values: func(t Ticket) []string { return []string{t.State} }},
+	"sprint":   {kind: query.KindEnum, values: func(t Ticket) []string { return t.Sprints }},
+	"sprints":  {kind: query.KindEnum, values: func(t Ticket) []string { return t.Sprints }},
+	"assignee": {kind: query.KindUser, values: func(t Ticket) []string { return personValues(t.Assignee) }},
+	"for":      {kind: query.KindUser, values: func(t Ticket) []string { return personValues(t.Assignee) }},
+	"reporter": {kind: query.KindUser, values: func(t Ticket) []string { return personValues(t.Reporter) }},
+	"by":       {kind: query.KindUser, values: func(t Ticket) []string { return personValues(t.Reporter) }},
+	"created":  {kind: query.KindDate, number: func(t Ticket) int64 { return t.Created }},
+	"updated":  {kind: query.KindDate, number: func(t Ticket) int64 { return t.Updated }},
+	"resolved": {kind: query.KindDate, number: func(t Ticket) int64 { return t.ResolvedAt }},
+}
+
+// queryKinds maps FieldValue kinds to how queries compare them
+var queryKinds = map[string]query.Kind{
+	FieldEnum:    query.KindEnum,
+	FieldUser:    query.KindUser,
+	FieldPeriod:  query.KindNumber,
+	FieldDate:    query.KindDate,
+	FieldInteger: query.KindNumber,
+	FieldFloat:   query.KindNumber,
+	FieldText:    query.KindText,
+}
+
+// customFieldKinds remembers the custom fields seen on cached tickets, so
+// queries can refer to them by name. Fields are never forgotten.
+type customFieldKinds struct {
+	mu    sync.RWMutex
+	kinds map[string]query.Kind // Ticket.CustomFields key → kind
+}
+
+// add records the custom fields of t
+func (c *customFieldKinds) add(t Ticket) {
+	c.mu.RLock()
+	missing := false
+	for key := range t.CustomFields {
+		if _, ok := c.kinds[key]; !ok {
+			missing = true
+			break
+		}
+	}
+	c.mu.RUnlock()
+	if !missing {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.kinds == nil {
+		c.kinds = map[string]query.Kind{}
+	}
+	for key, v := range t.CustomFields {
+		c.kinds[key] = queryKinds[v.Kind]
+	}
+}
+
+// lookup finds a custom field by name, ignoring case
+func (c *customFieldKinds) lookup(name string) (string, query.Kind, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for key, kind := range c.kinds {
+		if strings.EqualFold(key, name) {
+			return key, kind, true
+		}
+	}
+	return "", 0, false
+}
+
+// queryFieldsFor returns the field lookup for queries: built-in fields first,
+// then the YouTrack field names of the field mapping (e.g. "Typ"), then the
+// custom fields seen so far
+func queryFieldsFor(mapping fieldMapping, custom *customFieldKinds) query.Fields {
+	return func(name string) (string, query.Kind, bool) {
+		if f, ok := queryFields[name]; ok {
+			return name, f.kind, true
+		}
+		for ytName, target := range mapping {
+			if !strings.EqualFold(ytName, name) {
+				continue
+			}
+			if f, ok := queryFields[target]; ok {
+				return target, f.kind, true
+			}
+			name = target
+			break
+		}
+		return custom.lookup(name)
+	}
+}
+
+// ticketRecord evaluates queries against a Ticket
+type ticketRecord struct {
+	t Ticket
+}
+
+func (r ticketRecord) Values(field string) []string {
+	if f, ok := queryFields[field]; ok {
+		if f.values == nil {
+			return nil
+		}
+		return f.values(r.t)
+	}
+	v := r.t.CustomFields[field]
+	return append(v.Values[:len(v.Values):len(v.Values)], v.Logins...)
+}
+
+func (r ticketRecord) Number(field string) (float64, bool) {
+	if f, ok := queryFields[field]; ok {
+		if f.number == nil {
+			return 0, false
+		}
+		n := f.number(r.t)
+		return float64(n), n != 0
+	}
+	v, ok := r.t.CustomFields[field]
+	return v.Number, ok
+}
+
+func (r ticketRecord) Resolved() bool {
+	return r.t.Resolved
+}
+
+// TagValues lets #Bug, #Critical or #AGV match like in YouTrack
+func (r ticketRecord) TagValues() []string {
+	values := []string{r.t.Project(), r.t.Type, r.t.Priority, r.t.State}
+	values = append(values, r.t.Sprints...)
+	for _, v := range r.t.CustomFields {
+		if v.Kind == FieldEnum {
+			values = append(values, v.Values...)
+		}
+	}
+	return values
+}
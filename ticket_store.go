@@ -0,0 +1,232 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/zwoabier/youtrack-helper/internal/logger"
+)
+
+// subscriberBuffer is how many changes a subscriber may lag behind before its
+// backlog is replaced by a reset
+const subscriberBuffer = 16
+
+// StoreChange lists the ticket IDs affected by a single store mutation
+type StoreChange struct {
+	Added   []string `json:"added"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+	// Reset is only sent to subscribers: changes were coalesced because the
+	// subscriber fell behind, and it has to reload everything from Snapshot
+	Reset bool `json:"reset,omitempty"`
+}
+
+// Empty reports whether the mutation left the store unchanged
+func (c StoreChange) Empty() bool {
+	return !c.Reset && len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// TicketStore owns the in-memory ticket cache and is safe for concurrent use.
+// Slices returned by Snapshot are never modified: the first in-place change
+// after a Snapshot copies the ticket slice (copy-on-write). Snapshots are
+// capped at their length, so appending needs no copy and upserting a batch
+// costs time proportional to the batch, not to the store.
+type TicketStore struct {
+	mu      sync.RWMutex
+	tickets []Ticket
+	index   map[string]int // ticket ID -> position in tickets
+	shared  bool           // a snapshot may alias tickets
+
+	subsMu  sync.Mutex
+	subs    map[int]chan StoreChange
+	nextSub int
+}
+
+func NewTicketStore() *TicketStore {
+	return &TicketStore{
+		tickets: []Ticket{},
+		index:   map[string]int{},
+		subs:    map[int]chan StoreChange{},
+	}
+}
+
+// Snapshot returns the current tickets. Callers must not modify the returned slice.
+func (s *TicketStore) Snapshot() []Ticket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shared = true
+	return s.tickets[:len(s.tickets):len(s.tickets)]
+}
+
+// unshare copies tickets before an in-place change if a snapshot may alias
+// them. Callers hold s.mu.
+func (s *TicketStore) unshare() {
+	if s.shared {
+		s.tickets = slices.Clone(s.tickets)
+		s.shared = false
+	}
+}
+
+// Len returns the number of cached tickets
+func (s *TicketStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tickets)
+}
+
+// Get looks up a ticket by its readable ID (e.g. AGV-10)
+func (s *TicketStore) Get(id string) (Ticket, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.index[id]
+	if !ok {
+		return Ticket{}, false
+	}
+	return s.tickets[i], true
+}
+
+// Replace swaps in a complete new ticket set, e.g. after a full sync
+func (s *TicketStore) Replace(tickets []Ticket) StoreChange {
+	next := make([]Ticket, 0, len(tickets))
+	index := make(map[string]int, len(tickets))
+	for _, t := range tickets {
+		if i, dup := index[t.ID]; dup {
+			next[i] = t
+			continue
+		}
+		index[t.ID] = len(next)
+		next = append(next, t)
+	}
+
+	s.mu.Lock()
+	var change StoreChange
+	for _, t := range next {
+		if i, ok := s.index[t.ID]; !ok {
+			change.Added = append(change.Added, t.ID)
+		} else if !ticketEqual(s.tickets[i], t) {
+			change.Updated = append(change.Updated, t.ID)
+		}
+	}
+	for _, t := range s.tickets {
+		if _, ok := index[t.ID]; !ok {
+			change.Removed = append(change.Removed, t.ID)
+		}
+	}
+	s.tickets, s.index, s.shared = next, index, false
+	s.mu.Unlock()
+
+	s.publish(change)
+	return change
+}
+
+// Upsert inserts new tickets and replaces existing ones with the same ID
+func (s *TicketStore) Upsert(tickets ...Ticket) StoreChange {
+	s.mu.Lock()
+	var change StoreChange
+	for _, t := range tickets {
+		if i, ok := s.index[t.ID]; ok {
+			if !ticketEqual(s.tickets[i], t) {
+				s.unshare()
+				s.tickets[i] = t
+				change.Updated = append(change.Updated, t.ID)
+			}
+			continue
+		}
+		s.index[t.ID] = len(s.tickets)
+		s.tickets = append(s.tickets, t)
+		change.Added = append(change.Added, t.ID)
+	}
+	s.mu.Unlock()
+
+	s.publish(change)
+	return change
+}
+
+// Delete removes the tickets with the given IDs; unknown IDs are ignored
+func (s *TicketStore) Delete(ids ...string) StoreChange {
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+
+	s.mu.Lock()
+	var change StoreChange
+	if slices.ContainsFunc(ids, func(id string) bool { _, ok := s.index[id]; return ok }) {
+		// Compact in place, keeping the order of the remaining tickets
+		s.unshare()
+		kept := s.tickets[:0]
+		for _, t := range s.tickets {
+			if drop[t.ID] {
+				delete(s.index, t.ID)
+				change.Removed = append(change.Removed, t.ID)
+				continue
+			}
+			s.index[t.ID] = len(kept)
+			kept = append(kept, t)
+		}
+		clear(s.tickets[len(kept):])
+		s.tickets = kept
+	}
+	s.mu.Unlock()
+
+	s.publish(change)
+	return change
+}
+
+// Subscribe returns a channel receiving every non-empty change and a function
+// that ends the subscription. When a subscriber falls more than
+// subscriberBuffer changes behind, its pending changes are replaced by a
+// single StoreChange with Reset set, so no change is ever lost.
+func (s *TicketStore) Subscribe() (<-chan StoreChange, func()) {
+	ch := make(chan StoreChange, subscriberBuffer)
+	s.subsMu.Lock()
+	id := s.nextSub
+	s.nextSub++
+	s.subs[id] = ch
+	s.subsMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.subsMu.Lock()
+			delete(s.subs, id)
+			s.subsMu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (s *TicketStore) publish(change StoreChange) {
+	if change.Empty() {
+		return
+	}
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	for id, ch := range s.subs {
+		select {
+		case ch <- change:
+		default:
+			logger.Debug("ticket store: subscriber %d is not keeping up; asking it to reload", id)
+			// Only publish sends, under subsMu, so after draining the reset fits
+			drain(ch)
+			ch <- StoreChange{Reset: true}
+		}
+	}
+}
+
+// drain discards the changes buffered in ch
+func drain(ch chan StoreChange) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
+// ticketEqual reports whether two tickets carry the same data
+func ticketEqual(a, b Ticket) bool {
+	return reflect.DeepEqual(a, b)
+}
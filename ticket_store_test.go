@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTicketStoreMutations(t *testing.T) {
+	s := NewTicketStore()
+
+	change := s.Upsert(Ticket{ID: "A-1"}, Ticket{ID: "A-2"}, Ticket{ID: "A-3"})
+	if want := (StoreChange{Added: []string{"A-1", "A-2", "A-3"}}); !reflect.DeepEqual(change, want) {
+		t.Errorf("first Upsert = %+v, want %+v", change, want)
+	}
+	change = s.Upsert(Ticket{ID: "A-2", Summary: "changed"}, Ticket{ID: "A-3"}, Ticket{ID: "A-4"})
+	if want := (StoreChange{Added: []string{"A-4"}, Updated: []string{"A-2"}}); !reflect.DeepEqual(change, want) {
+		t.Errorf("second Upsert = %+v, want %+v", change, want)
+	}
+	change = s.Delete("A-1", "A-3", "A-9")
+	if want := (StoreChange{Removed: []string{"A-1", "A-3"}}); !reflect.DeepEqual(change, want) {
+		t.Errorf("Delete = %+v, want %+v", change, want)
+	}
+	if got, want := ticketIDs(s.Snapshot()), []string{"A-2", "A-4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot = %v, want %v", got, want)
+	}
+	if got, ok := s.Get("A-2"); !ok || got.Summary != "changed" {
+		t.Errorf("Get(A-2) = %+v, %v", got, ok)
+	}
+	if _, ok := s.Get("A-1"); ok {
+		t.Error("Get(A-1) found a deleted ticket")
+	}
+
+	change = s.Replace([]Ticket{{ID: "A-4"}, {ID: "A-5"}})
+	if want := (StoreChange{Added: []string{"A-5"}, Removed: []string{"A-2"}}); !reflect.DeepEqual(change, want) {
+		t.Errorf("Replace = %+v, want %+v", change, want)
+	}
+}
+
+func TestTicketStoreSnapshotIsStable(t *testing.T) {
+	s := NewTicketStore()
+	s.Upsert(Ticket{ID: "A-1", Summary: "one"}, Ticket{ID: "A-2", Summary: "two"}, Ticket{ID: "A-3", Summary: "three"})
+	snap := s.Snapshot()
+	want := append([]Ticket(nil), snap...)
+
+	s.Upsert(Ticket{ID: "A-2", Summary: "changed"}, Ticket{ID: "A-4"})
+	s.Delete("A-1")
+	s.Upsert(Ticket{ID: "A-5"})
+	if !reflect.DeepEqual(snap, want) {
+		t.Errorf("snapshot changed by later mutations: %+v, want %+v", snap, want)
+	}
+	if got := ticketIDs(s.Snapshot()); !reflect.DeepEqual(got, []string{"A-2", "A-3", "A-4", "A-5"}) {
+		t.Errorf("store = %v", got)
+	}
+}
+
+// TestTicketStoreConcurrent runs writers, readers and a subscriber at once;
+// run it with -race
+func TestTicketStoreConcurrent(t *testing.T) {
+	const (
+		writers = 4
+		batches = 50
+		batch   = 20
+	)
+	s := NewTicketStore()
+	changes, unsubscribe := s.Subscribe()
+	received := make(chan int)
+	go func() {
+		n := 0
+		for range changes {
+			n++
+		}
+		received <- n
+	}()
+
+	var writersWG, readersWG sync.WaitGroup
+	done := make(chan struct{})
+	for r := 0; r < 2; r++ {
+		readersWG.Add(1)
+		go func() {
+			defer readersWG.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snap := s.Snapshot()
+				seen := make(map[string]bool, len(snap))
+				for _, tk := range snap {
+					if seen[tk.ID] {
+						t.Errorf("snapshot lists %s twice", tk.ID)
+						return
+					}
+					seen[tk.ID] = true
+				}
+				if len(snap) > 0 {
+					if _, ok := s.Get(snap[0].ID); !ok && !isOdd(snap[0].ID) {
+						t.Errorf("Get(%s) missed a ticket that is never deleted", snap[0].ID)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for w := 0; w < writers; w++ {
+		writersWG.Add(1)
+		go func(w int) {
+			defer writersWG.Done()
+			for b := 0; b < batches; b++ {
+				tickets := make([]Ticket, batch)
+				var odd []string
+				for i := range tickets {
+					n := b*batch + i
+					tickets[i] = Ticket{ID: fmt.Sprintf("W%d-%d", w, n), Summary: "v1"}
+					if n%2 == 1 {
+						odd = append(odd, tickets[i].ID)
+					}
+				}
+				s.Upsert(tickets...)
+				// Update the even tickets again and drop the odd ones
+				for i := range tickets {
+					tickets[i].Summary = "v2"
+				}
+				s.Upsert(tickets...)
+				s.Delete(odd...)
+			}
+		}(w)
+	}
+	writersWG.Wait()
+	close(done)
+	readersWG.Wait()
+	unsubscribe()
+	if n := <-received; n == 0 {
+		t.Error("subscriber received no changes")
+	}
+
+	snap := s.Snapshot()
+	if len(snap) != writers*batches*batch/2 {
+		t.Fatalf("store has %d tickets, want %d", len(snap), writers*batches*batch/2)
+	}
+	for _, tk := range snap {
+		if isOdd(tk.ID) || tk.Summary != "v2" {
+			t.Fatalf("unexpected ticket %+v", tk)
+		}
+		if got, ok := s.Get(tk.ID); !ok || !reflect.DeepEqual(got, tk) {
+			t.Fatalf("Get(%s) = %+v, %v", tk.ID, got, ok)
+		}
+	}
+}
+
+func isOdd(id string) bool {
+	var w, n int
+	fmt.Sscanf(id, "W%d-%d", &w, &n)
+	return n%2 == 1
+}
+
+func TestTicketStoreSubscriberOverflow(t *testing.T) {
+	s := NewTicketStore()
+	changes, unsubscribe := s.Subscribe()
+	defer unsubscribe()
+
+	// Nobody reads while the store changes more often than the buffer holds
+	for i := 0; i < 3*subscriberBuffer; i++ {
+		s.Upsert(Ticket{ID: fmt.Sprintf("A-%d", i)})
+	}
+	if n := len(changes); n > subscriberBuffer {
+		t.Fatalf("%d changes buffered, want at most %d", n, subscriberBuffer)
+	}
+	reset := false
+	for len(changes) > 0 {
+		if (<-changes).Reset {
+			reset = true
+		}
+	}
+	if !reset {
+		t.Fatal("subscriber that fell behind got no reset")
+	}
+
+	// Delivery continues normally afterwards
+	s.Delete("A-0")
+	if change := <-changes; change.Reset || !reflect.DeepEqual(change.Removed, []string{"A-0"}) {
+		t.Errorf("change after reset = %+v", change)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zwoabier/youtrack-helper/internal/atomicfile"
+	"github.com/zwoabier/youtrack-helper/internal/search"
+)
+
+// Ticket usage actions recorded with RecordUsage
+const (
+	UsageCopy   = "copy"   // markdown link copied
+	UsageOpen   = "open"   // opened in the browser
+	UsageSelect = "select" // moved to with the arrow keys and left without copying or opening
+)
+
+// usageWeights is how much one use of each action adds to a ticket's frecency
+var usageWeights = map[string]float64{
+	UsageCopy:   1,
+	UsageOpen:   1,
+	UsageSelect: 0.5,
+}
+
+const (
+	// usageHalfLife is how long it takes a ticket's frecency to halve
+	usageHalfLife = 7 * 24 * time.Hour
+	// maxUsageEntries caps the tickets kept in the usage history
+	maxUsageEntries = 2000
+	// maxFrecencyBoost is the search score a very frequently used ticket gets
+	// on top of its relevance: enough to beat a better summary match, not an
+	// exact ID match
+	maxFrecencyBoost = 300
+)
+
+// ticketUsage is the usage history of one ticket
+type ticketUsage struct {
+	Score float64 `json:"score"` // frecency as of Last
+	Count int     `json:"count"` // uses recorded
+	Last  int64   `json:"last"`  // unix seconds of the latest use
+}
+
+// frecency returns the score decayed to at
+func (u ticketUsage) frecency(at time.Time) float64 {
+	age := at.Sub(time.Unix(u.Last, 0))
+	if age < 0 {
+		age = 0
+	}
+	return u.Score * math.Exp2(-float64(age)/float64(usageHalfLife))
+}
+
+// usageStore records which tickets are copied, opened and selected, and
+// ranks them by frecency: every use adds its weight, and the sum halves every
+// usageHalfLife. It is persisted as JSON per instance.
+type usageStore struct {
+	mu      sync.Mutex
+	path    string
+	tickets map[string]ticketUsage
+}
+
+// Open switches the store to the file at path and reads it; a missing file
+// is an empty history. An empty path disables persistence.
+func (u *usageStore) Open(path string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.path = path
+	u.tickets = map[string]ticketUsage{}
+	if path == "" {
+		return nil
+	}
+	var tickets map[string]ticketUsage
+	_, err := atomicfile.Read(path, func(data []byte) error {
+		tickets = nil
+		return json.Unmarshal(data, &tickets)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read usage history: %w", err)
+	}
+	if tickets != nil {
+		u.tickets = tickets
+	}
+	return nil
+}
+
+// Record adds one use of ticket id by action at the given time and saves the history
+func (u *usageStore) Record(id, action string, at time.Time) error {
+	weight, ok := usageWeights[action]
+	if !ok {
+		return fmt.Errorf("Unknown usage action %q.", action)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.tickets == nil {
+		u.tickets = map[string]ticketUsage{}
+	}
+	t := u.tickets[id]
+	u.tickets[id] = ticketUsage{Score: t.frecency(at) + weight, Count: t.Count + 1, Last: at.Unix()}
+	u.trim(at)
+	return u.save()
+}
+
+// Clear forgets all usage and removes the saved history
+func (u *usageStore) Clear() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.tickets = map[string]ticketUsage{}
+	if u.path == "" {
+		return nil
+	}
+	// The backup generation goes too, or the next Open would restore it
+	for _, path := range []string{u.path, u.path + atomicfile.BackupSuffix} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to clear usage history: %w", err)
+		}
+	}
+	return nil
+}
+
+// Boost returns the search boost of each used ticket as of at. The scores
+// are copied, so the boost can be used without holding the store's lock.
+func (u *usageStore) Boost(at time.Time) search.Boost {
+	u.mu.Lock()
+	scores := make(map[string]float64, len(u.tickets))
+	for id, t := range u.tickets {
+		scores[id] = t.frecency(at)
+	}
+	u.mu.Unlock()
+	return func(id string) float64 {
+		f := scores[id]
+		// Saturates: one recent use gives half the maximum
+		return maxFrecencyBoost * f / (f + 1)
+	}
+}
+
+// trim drops the least frecent tickets beyond maxUsageEntries. Callers hold u.mu.
+func (u *usageStore) trim(at time.Time) {
+	if len(u.tickets) <= maxUsageEntries {
+		return
+	}
+	ids := make([]string, 0, len(u.tickets))
+	for id := range u.tickets {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return u.tickets[ids[i]].frecency(at) > u.tickets[ids[j]].frecency(at) })
+	for _, id := range ids[maxUsageEntries:] {
+		delete(u.tickets, id)
+	}
+}
+
+// save writes the history to disk. Callers hold u.mu.
+func (u *usageStore) save() error {
+	if u.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(u.tickets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage history: %w", err)
+	}
+	return atomicfile.Write(u.path, data, 0600)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zwoabier/youtrack-helper/internal/search"
+)
+
+func TestUsageBoostRanking(t *testing.T) {
+	ix := search.NewIndex()
+	ix.Upsert(
+		search.Document{ID: "AGV-1", Summary: "Printer driver crashes"},
+		search.Document{ID: "AGV-2", Summary: "Printer setup crashes"},
+		search.Document{ID: "AGV-3", Summary: "Printer"},
+	)
+	now := time.Now()
+	var u usageStore
+	if err := u.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Record("AGV-1", UsageCopy, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := func(results []search.Result) []string {
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	// A recently used ticket outranks an equal textual match
+	if got := ids(ix.Search("printer crashes", 0, u.Boost(now))); !reflect.DeepEqual(got, []string{"AGV-1", "AGV-2"}) {
+		t.Errorf("ranking = %v, want AGV-1 first", got)
+	}
+	// It also leads the empty-query listing
+	if got := ids(ix.Search("", 1, u.Boost(now))); !reflect.DeepEqual(got, []string{"AGV-1"}) {
+		t.Errorf("empty query = %v", got)
+	}
+	// but not a clearly better match
+	if got := ids(ix.Search("agv-3", 1, u.Boost(now))); !reflect.DeepEqual(got, []string{"AGV-3"}) {
+		t.Errorf("exact ID = %v", got)
+	}
+}
+
+func TestUsageFrecency(t *testing.T) {
+	// Usage times are kept in whole seconds
+	now := time.Unix(time.Now().Unix(), 0)
+	var u usageStore
+	u.Open("")
+	u.Record("AGV-1", UsageCopy, now.Add(-usageHalfLife))
+	u.Record("AGV-2", UsageSelect, now)
+	u.Record("AGV-3", UsageOpen, now)
+	u.Record("AGV-3", UsageOpen, now)
+	if err := u.Record("AGV-4", "print", now); err == nil {
+		t.Error("unknown action was accepted")
+	}
+
+	boost := u.Boost(now)
+	for _, tt := range []struct {
+		id       string
+		frecency float64
+	}{
+		{id: "AGV-1", frecency: 0.5}, // one copy, a half-life ago
+		{id: "AGV-2", frecency: 0.5}, // one selection now
+		{id: "AGV-3", frecency: 2},
+		{id: "AGV-4", frecency: 0},
+	} {
+		want := maxFrecencyBoost * tt.frecency / (tt.frecency + 1)
+		if got := boost(tt.id); math.Abs(got-want) > 1e-6 {
+			t.Errorf("boost(%s) = %f, want %f", tt.id, got, want)
+		}
+	}
+}
+
+func TestUsagePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), usageFile)
+	now := time.Now()
+	var u usageStore
+	if err := u.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	u.Record("AGV-1", UsageCopy, now)
+
+	var reopened usageStore
+	if err := reopened.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Boost(now)("AGV-1") == 0 {
+		t.Error("usage lost on reopen")
+	}
+
+	// A second write leaves a backup generation, which Clear removes too
+	u.Record("AGV-1", UsageCopy, now)
+	if err := u.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Boost(now)("AGV-1") != 0 {
+		t.Error("usage survived Clear")
+	}
+}
@@ -1,5 +1,12 @@
 package main
 
+import (
+	"strings"
+
+	"github.com/zwoabier/youtrack-helper/internal/query"
+	"github.com/zwoabier/youtrack-helper/internal/search"
+)
+
 type Config struct {
 	BaseURL      string   `json:"base_url"`
 	Projects     []string `json:"projects"`
@@ -7,6 +14,37 @@ type Config struct {
 	LastSyncTime int64    `json:"last_sync_time"`
 	LogLevel     string   `json:"log_level"`   // "debug", "info", "warn", "error"; default "info"
 	LogToFile    bool     `json:"log_to_file"` // when true, also write to ~/.youtrack-helper/app.log
+
+	LastFullSyncTime      int64 `json:"last_full_sync_time"`      // unix seconds of the last complete resync
+	FullSyncIntervalHours int   `json:"full_sync_interval_hours"` // hours between full resyncs; 0 means 24
+	SyncIntervalMinutes   int   `json:"sync_interval_minutes"`    // minutes between background syncs; 0 means 5
+	PerProjectSync        bool  `json:"per_project_sync"`         // query each project separately so one failing project doesn't fail the sync
+
+	CacheBackend string `json:"cache_backend"` // "json" (default) or "bolt" for large instances
+
+	RateLimitPerSecond    float64 `json:"rate_limit_per_second"`   // API requests per second; 0 means 10
+	RateLimitBurst        int     `json:"rate_limit_burst"`        // requests allowed back to back; 0 means 20
+	MaxConcurrentRequests int     `json:"max_concurrent_requests"` // API requests in flight; 0 means 4
+
+	HTTPTimeoutSeconds int    `json:"http_timeout_seconds"` // overall per-request timeout; 0 means 120
+	DialTimeoutSeconds int    `json:"dial_timeout_seconds"` // TCP connect timeout; 0 means 10
+	TLSTimeoutSeconds  int    `json:"tls_timeout_seconds"`  // TLS handshake timeout; 0 means 10
+	Proxy              string `json:"proxy"`                // proxy URL; empty uses HTTP(S)_PROXY, "direct" disables it
+	CABundle           string `json:"ca_bundle"`            // path to an extra PEM CA bundle, e.g. for a self-signed server
+	ClientCert         string `json:"client_cert"`          // path to a PEM client certificate
+	ClientKey          string `json:"client_key"`           // path to the PEM key for ClientCert; empty if it is in the same file
+	DisableGzip        bool   `json:"disable_gzip"`         // don't request gzip-compressed responses
+
+	// Ticket search normalization; everything is enabled by default
+	DisableUmlautFolding      bool `json:"disable_umlaut_folding"`      // don't match ä/ö/ü/ß with ae/oe/ue/ss
+	DisableDiacriticStripping bool `json:"disable_diacritic_stripping"` // don't match accented letters with their base letter
+	DisableStemming           bool `json:"disable_stemming"`            // don't strip German and English word endings
+	DisableCompoundSplitting  bool `json:"disable_compound_splitting"`  // don't match parts of compound words
+
+	// FieldMapping maps YouTrack custom field names to Ticket attributes
+	// ("type", "priority", "sprints", "state", "assignee") or to the key used in Ticket.CustomFields,
+	// e.g. {"Typ": "type", "Story points": "story_points"}. Merged over the defaults.
+	FieldMapping map[string]string `json:"field_mapping"`
 }
 
 type Ticket struct {
@@ -16,6 +54,31 @@ type Ticket struct {
 	Priority string   `json:"priority"` // Parsed from customFields
 	Sprints  []string `json:"sprints"`  // Parsed from customFields
 	Url      string   `json:"url"`      // Computed or fetched
+
+	State      string `json:"state"`       // Parsed from customFields
+	Resolved   bool   `json:"resolved"`    // true once the issue reached a resolved state
+	Assignee   Person `json:"assignee"`    // Parsed from customFields
+	Reporter   Person `json:"reporter"`    // Issue reporter
+	Created    int64  `json:"created"`     // unix millis
+	Updated    int64  `json:"updated"`     // unix millis
+	ResolvedAt int64  `json:"resolved_at"` // unix millis; 0 while unresolved
+
+	// CustomFields holds every custom field not mapped to an attribute above
+	CustomFields map[string]FieldValue `json:"custom_fields,omitempty"`
+}
+
+// Person is a YouTrack user referenced by a ticket
+type Person struct {
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+}
+
+// Project returns the project short name from the readable ID (AGV for AGV-10)
+func (t Ticket) Project() string {
+	if i := strings.LastIndex(t.ID, "-"); i > 0 {
+		return t.ID[:i]
+	}
+	return ""
 }
 
 // Project represents a YouTrack project returned by the admin/projects endpoint
@@ -29,7 +92,85 @@ type Project struct {
 // User represents a YouTrack user returned by the /api/users/me endpoint
 type User struct {
 	ID    string `json:"id"`
+	Login string `json:"login"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
 	Type  string `json:"$type"`
 }
+
+// defaultSearchLimit is how many results SearchTickets returns when no limit is given
+const defaultSearchLimit = 50
+
+// SearchResponse is the answer of SearchTickets
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+	Errors  []query.Error  `json:"errors"` // syntax problems in the query, as character ranges to underline
+}
+
+// SearchResult is one hit of SearchTickets
+type SearchResult struct {
+	Ticket     Ticket             `json:"ticket"`
+	Score      float64            `json:"score"`
+	Highlights []search.Highlight `json:"highlights"` // matched character ranges per field
+	Cached     bool               `json:"cached"`     // false for SearchRemote hits outside the local cache
+}
+
+// defaultTicketPageSize is how many tickets GetTicketsPage returns when no limit is given
+const defaultTicketPageSize = 500
+
+// TicketPage is one page of GetTicketsPage
+type TicketPage struct {
+	Tickets []Ticket `json:"tickets"`
+	Next    string   `json:"next"` // cursor of the following page; "" after the last page
+}
+
+// APIStats reports client-side API throttling counters
+type APIStats struct {
+	DelayedRequests int64 `json:"delayed_requests"` // requests held back by the rate limit or concurrency cap
+}
+
+// SyncProgress reports how far a running ticket sync has got
+type SyncProgress struct {
+	Page      int `json:"page"`       // 1-based page number just received
+	PageSize  int `json:"page_size"`  // $top used per request
+	PageCount int `json:"page_count"` // issues in this page
+	Fetched   int `json:"fetched"`    // issues received so far
+}
+
+// Events emitted to the frontend while syncing
+const (
+	EventSyncStarted   = "sync:started"   // SyncStarted
+	EventSyncProgress  = "sync:progress"  // SyncProgress, once per fetched page
+	EventSyncCompleted = "sync:completed" // SyncCompleted
+	EventSyncFailed    = "sync:failed"    // SyncFailed
+)
+
+// SyncStarted is the payload of EventSyncStarted
+type SyncStarted struct {
+	Full bool `json:"full"` // full resync rather than delta
+}
+
+// SyncCompleted is the payload of EventSyncCompleted
+type SyncCompleted struct {
+	Full    bool `json:"full"`
+	Added   int  `json:"added"`
+	Updated int  `json:"updated"`
+	Removed int  `json:"removed"`
+	Total   int  `json:"total"` // tickets in the cache after the sync
+
+	Projects []ProjectSyncResult `json:"projects,omitempty"` // per-project outcomes when Config.PerProjectSync is on
+}
+
+// ProjectSyncResult is the outcome of syncing one project in per-project mode
+type ProjectSyncResult struct {
+	Project string `json:"project"` // project short name, e.g. AGV
+	Fetched int    `json:"fetched"` // issues received for the project
+	Error   string `json:"error"`   // user-facing failure message; empty on success
+	Status  int    `json:"status"`  // HTTP status of the failed request, 0 if none
+}
+
+// SyncFailed is the payload of EventSyncFailed
+type SyncFailed struct {
+	Full    bool   `json:"full"`
+	Message string `json:"message"` // user-facing error message
+}
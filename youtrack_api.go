@@ -2,37 +2,85 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/zwoabier/youtrack-helper/internal/logger"
-	"os"
-	"time"
+	"github.com/zwoabier/youtrack-helper/internal/youtrack"
 )
 
 type YouTrackAPI struct {
-	cm             *ConfigManager
-	cachedTickets []Ticket
-	http           *http.Client
+	cm     *ConfigManager
+	store  *TicketStore
+	limits *youtrack.LimitTransport
+
+	mu           sync.RWMutex
+	http         *http.Client
+	transportErr error // why the configured connection settings couldn't be applied
 }
 
-func NewYouTrackAPI(cm *ConfigManager) *YouTrackAPI {
-	return &YouTrackAPI{
-		cm:             cm,
-		cachedTickets: []Ticket{},
-		http:           &http.Client{},
+func NewYouTrackAPI(cm *ConfigManager, store *TicketStore) *YouTrackAPI {
+	yt := &YouTrackAPI{
+		cm:     cm,
+		store:  store,
+		limits: youtrack.NewLimitTransport(nil),
+	}
+	if err := yt.ApplyConfig(cm.GetConfig()); err != nil {
+		// Keep working with default connection settings; ValidateConnection reports the problem
+		logger.Warn("YouTrack connection settings: %v", err)
+		yt.mu.Lock()
+		yt.http = yt.newHTTPClient(http.DefaultTransport, 0)
+		yt.mu.Unlock()
+	}
+	return yt
+}
+
+// ApplyConfig updates the rate limits and connection settings from cfg. If
+// the connection settings are invalid the previous ones stay in effect and
+// the returned error names the setting at fault.
+func (yt *YouTrackAPI) ApplyConfig(cfg Config) error {
+	yt.limits.SetLimits(cfg.RateLimitPerSecond, cfg.RateLimitBurst, cfg.MaxConcurrentRequests)
+
+	transport, err := youtrack.NewTransport(youtrack.TransportOptions{
+		DialTimeout: time.Duration(cfg.DialTimeoutSeconds) * time.Second,
+		TLSTimeout:  time.Duration(cfg.TLSTimeoutSeconds) * time.Second,
+		Proxy:       cfg.Proxy,
+		CAFile:      cfg.CABundle,
+		CertFile:    cfg.ClientCert,
+		KeyFile:     cfg.ClientKey,
+		DisableGzip: cfg.DisableGzip,
+	})
+	yt.mu.Lock()
+	defer yt.mu.Unlock()
+	yt.transportErr = err
+	if err != nil {
+		var tErr *youtrack.TransportError
+		if errors.As(err, &tErr) {
+			return &apiUserError{msg: fmt.Sprintf("Invalid %s setting: %v", tErr.Setting, tErr.Err), err: err}
+		}
+		return err
 	}
+	yt.http = yt.newHTTPClient(transport, time.Duration(cfg.HTTPTimeoutSeconds)*time.Second)
+	return nil
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// newHTTPClient chains retries and client-side limits in front of transport.
+// Every retry attempt passes through the limiter on its own.
+func (yt *YouTrackAPI) newHTTPClient(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = youtrack.DefaultRequestTimeout
 	}
-	return b
+	yt.limits.SetBase(transport)
+	return &http.Client{Transport: youtrack.NewRetryTransport(yt.limits), Timeout: timeout}
+}
+
+// DelayedRequests returns how many API requests were held back by the client-side limits
+func (yt *YouTrackAPI) DelayedRequests() int64 {
+	return yt.limits.Delayed()
 }
 
 // userMessageForStatus returns a short, actionable message for the user.
@@ -40,8 +88,12 @@ func userMessageForStatus(statusCode int) string {
 	switch {
 	case statusCode == 401:
 		return "Invalid token. Check your YouTrack permanent token."
+	case statusCode == 403:
+		return "Permission denied. The token's user lacks access to this YouTrack resource."
 	case statusCode == 404:
 		return "YouTrack URL or API path may be wrong. Check the base URL."
+	case statusCode == 429:
+		return "YouTrack is rate limiting requests. Try again in a minute."
 	case statusCode >= 500:
 		return "YouTrack server error. Try again later."
 	default:
@@ -49,342 +101,482 @@ func userMessageForStatus(statusCode int) string {
 	}
 }
 
-// SyncTickets fetches tickets from YouTrack API and updates cache
-func (yt *YouTrackAPI) SyncTickets(ctx context.Context) error {
-	cfg := yt.cm.GetConfig()
-	token := yt.cm.GetToken()
-
-	if cfg.BaseURL == "" || token == "" {
-		return fmt.Errorf("YouTrack is not configured. Complete setup first.")
-	}
-
-	baseURL := normalizeBaseURL(cfg.BaseURL)
-
-	// Debug log: SyncTickets entry (H1)
-	writeDebugND := func(location, message string, data map[string]interface{}, hypothesisId string) {
-		// minimal inline writer to avoid extra imports
-		payload := map[string]interface{}{
-			"sessionId":    "debug-session",
-			"runId":        "run1",
-			"hypothesisId": hypothesisId,
-			"location":     location,
-			"message":      message,
-			"data":         data,
-			"timestamp":    time.Now().UnixMilli(),
-		}
-		b, _ := json.Marshal(payload)
-		f, err := os.OpenFile("/home/menzelm/Projects/youtrack-helper/.cursor/debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if err == nil {
-			f.Write(b)
-			f.Write([]byte("\n"))
-			f.Close()
-		}
+// userMessageForError returns the message shown to the user for an API
+// error, naming the stage of the connection that failed
+func userMessageForError(err *youtrack.APIError) string {
+	switch err.Stage() {
+	case youtrack.StageResponse:
+		return userMessageForStatus(err.Status)
+	case youtrack.StageProxy:
+		return "Could not connect through the proxy. Check the proxy setting."
+	case youtrack.StageDNS:
+		return "YouTrack host not found. Check the base URL."
+	case youtrack.StageConnect:
+		return "Could not connect to YouTrack. Check the base URL and your network."
+	case youtrack.StageTLS:
+		return "Secure connection to YouTrack failed. Check the URL, CA bundle and client certificate."
+	case youtrack.StageTimeout:
+		return "YouTrack did not respond in time. Check your network or raise the request timeout."
+	default:
+		return "Connection failed. Check your network and YouTrack URL."
 	}
-	writeDebugND("youtrack_api.go:SyncTickets", "entry", map[string]interface{}{"projectsLen": len(cfg.Projects)}, "H1")
+}
 
-	// Ensure projects are selected
-	if len(cfg.Projects) == 0 {
-		logger.Info("SyncTickets: no projects selected; skipping sync")
-		return fmt.Errorf("No projects selected. Complete setup to enable sync.")
-	}
+// apiUserError carries the user-facing message for the frontend while still
+// unwrapping to the underlying *youtrack.APIError
+type apiUserError struct {
+	msg string
+	err error
+}
+
+func (e *apiUserError) Error() string { return e.msg }
+func (e *apiUserError) Unwrap() error { return e.err }
+
+// errSyncCancelled is returned when the sync context is cancelled between or during pages.
+var errSyncCancelled = errors.New("Sync cancelled.")
 
-	// Build YouTrack API query
-	projectQuery := strings.Join(cfg.Projects, " or project: ")
-	queryStr := fmt.Sprintf("project: %s", projectQuery)
+// errSearchCancelled is returned when a remote search is superseded by a newer one.
+var errSearchCancelled = errors.New("Search cancelled.")
 
-	// Construct URL
-	apiURL := fmt.Sprintf("%s/api/issues?query=%s&fields=idReadable,summary,customFields(name,value(name))&$top=5000",
-		baseURL,
-		url.QueryEscape(queryStr),
-	)
+// SyncOptions controls a single SyncTickets run.
+type SyncOptions struct {
+	// Since limits the sync to issues updated at or after this time and merges
+	// them into the existing cache. The zero value requests a full resync.
+	Since time.Time
+	// Progress, if non-nil, is called after every fetched page.
+	Progress func(SyncProgress)
+}
+
+// youtrackQueryTime is the date-time layout accepted by the YouTrack query language.
+// YouTrack reads it in the timezone of the user's profile.
+const youtrackQueryTime = "2006-01-02T15:04:05"
+
+// fallbackQueryZone is used when the profile timezone can't be fetched. A
+// time written in the westernmost zone is never later in any other zone, so
+// a delta sync fetches up to a day too much instead of missing updates.
+var fallbackQueryZone = time.FixedZone("UTC-12", -12*60*60)
 
-	// Create request
-	logger.Debug("SyncTickets: sending request to %s", apiURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+// queryZone returns the timezone YouTrack reads query dates in for client's user
+func queryZone(ctx context.Context, client *youtrack.Client) *time.Location {
+	loc, err := client.GetTimezone(ctx)
 	if err != nil {
-		logger.Error("SyncTickets: failed to create request: %v", err)
-		return fmt.Errorf("Failed to prepare sync.")
+		logger.Warn("SyncTickets: profile timezone unknown, widening the delta sync: %v", err)
+		return fallbackQueryZone
 	}
+	return loc
+}
 
-	// Set authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/json")
+// client returns a YouTrack REST client for the given instance
+func (yt *YouTrackAPI) client(baseURL, token string) *youtrack.Client {
+	yt.mu.RLock()
+	defer yt.mu.RUnlock()
+	return youtrack.New(baseURL, token, yt.http)
+}
 
-	// Execute request
-	logger.Debug("SyncTickets: executing HTTP request")
-	resp, err := yt.http.Do(req)
-	if err != nil {
-		logger.Error("SyncTickets: request failed: %v", err)
-		return fmt.Errorf("Connection failed. Check your network and YouTrack URL.")
-	}
-	defer resp.Body.Close()
-
-	bodyBytes, _ := io.ReadAll(resp.Body)
-	// NDJSON debug entry for HTTP response (avoid logging tokens)
-	func() {
-		payload := map[string]interface{}{
-			"sessionId":    "debug-session",
-			"runId":        "run1",
-			"hypothesisId": "H2",
-			"location":     "youtrack_api.go:SyncTickets",
-			"message":      "http_response",
-			"data": map[string]interface{}{
-				"status": resp.StatusCode,
-				"url":    apiURL,
-				"body":   string(bodyBytes[:min(len(bodyBytes), 1000)]),
-			},
-			"timestamp": time.Now().UnixMilli(),
-		}
-		b, _ := json.Marshal(payload)
-		f, _ := os.OpenFile("/home/menzelm/Projects/youtrack-helper/.cursor/debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if f != nil {
-			f.Write(b)
-			f.Write([]byte("\n"))
-			f.Close()
+// userError logs err and converts it into the short, actionable message
+// shown to the user. The original error stays reachable via errors.As.
+func userError(op string, err error) error {
+	logger.Error("%s: %v", op, err)
+	var apiErr *youtrack.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return &apiUserError{msg: userMessageForError(apiErr), err: err}
+	case errors.Is(err, youtrack.ErrInvalidURL):
+		return &apiUserError{msg: "Invalid YouTrack URL.", err: err}
+	case errors.Is(err, youtrack.ErrInvalidResponse):
+		return &apiUserError{msg: "Invalid response from YouTrack. Try again later.", err: err}
+	default:
+		return &apiUserError{msg: "Connection failed. Check your network and YouTrack URL.", err: err}
+	}
+}
+
+// SyncResult is the outcome of a SyncTickets run
+type SyncResult struct {
+	Change StoreChange
+	// Projects holds one entry per configured project in per-project mode; nil otherwise
+	Projects []ProjectSyncResult
+}
+
+// Partial reports whether some, but not all, projects failed to sync
+func (r SyncResult) Partial() bool {
+	for _, p := range r.Projects {
+		if p.Error != "" {
+			return true
 		}
-	}()
+	}
+	return false
+}
+
+// SyncTickets fetches tickets from YouTrack API and updates cache.
+// Issues are fetched page by page until YouTrack returns a short page, and
+// each response is decoded as a stream: parsed tickets are upserted into the
+// store in batches of syncBatchSize while the download is still running.
+// A full sync then removes the tickets YouTrack no longer returned; a delta
+// sync (opts.Since set) only upserts. A cancelled or failed sync removes
+// nothing, and its result still lists the changes already applied. The
+// returned change lists the ticket IDs the sync added, updated and removed.
+//
+// With Config.PerProjectSync each project is fetched by its own query,
+// concurrently. A project that fails doesn't fail the sync: its cached
+// tickets are kept and its error is reported in SyncResult.Projects. Only
+// when every project fails is an error returned.
+func (yt *YouTrackAPI) SyncTickets(ctx context.Context, opts SyncOptions) (SyncResult, error) {
+	cfg := yt.cm.GetConfig()
+	token := yt.cm.GetToken()
 
-	if resp.StatusCode != http.StatusOK {
-		logger.Error("SyncTickets: YouTrack API error status=%d url=%s body=%s", resp.StatusCode, apiURL, string(bodyBytes))
-		return fmt.Errorf("%s", userMessageForStatus(resp.StatusCode))
+	if cfg.BaseURL == "" || token == "" {
+		return SyncResult{}, fmt.Errorf("YouTrack is not configured. Complete setup first.")
 	}
 
-	var issues []map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &issues); err != nil {
-		logger.Error("SyncTickets: decode error: %v", err)
-		return fmt.Errorf("Invalid response from YouTrack. Try again later.")
+	client := yt.client(cfg.BaseURL, token)
+
+	// Ensure projects are selected
+	if len(cfg.Projects) == 0 {
+		logger.Info("SyncTickets: no projects selected; skipping sync")
+		return SyncResult{}, fmt.Errorf("No projects selected. Complete setup to enable sync.")
 	}
-	logger.Debug("SyncTickets: received %d issues", len(issues))
-	// NDJSON debug: list first 5 issue IDs
-	func() {
-		sample := []string{}
-		for i, it := range issues {
-			if i >= 5 {
-				break
-			}
-			if id, ok := it["idReadable"].(string); ok {
-				sample = append(sample, id)
-			}
+
+	delta := !opts.Since.IsZero()
+	since := opts.Since
+	if delta {
+		since = since.In(queryZone(ctx, client))
+		logger.Info("SyncTickets: delta sync since %s", since.Format(time.RFC3339))
+	}
+	progress := newSyncProgressCounter(opts.Progress)
+	sink := newTicketSink(yt.store, client.BaseURL(), newFieldMapping(cfg.FieldMapping))
+
+	var result SyncResult
+	if cfg.PerProjectSync {
+		var err error
+		result.Projects, err = yt.fetchPerProject(ctx, client, cfg.Projects, since, sink, progress)
+		if err != nil {
+			return SyncResult{Change: sink.finish()}, err
 		}
-		payload := map[string]interface{}{
-			"sessionId":    "debug-session",
-			"runId":        "run1",
-			"hypothesisId": "H2",
-			"location":     "youtrack_api.go:SyncTickets",
-			"message":      "parsed_issues_sample",
-			"data": map[string]interface{}{
-				"count":  len(issues),
-				"sample": sample,
-			},
-			"timestamp": time.Now().UnixMilli(),
+	} else {
+		projectQuery := strings.Join(cfg.Projects, " or project: ")
+		queryStr := syncQuery(fmt.Sprintf("project: %s", projectQuery), since)
+		n, err := fetchTickets(ctx, client, queryStr, sink, progress)
+		if ctx.Err() != nil {
+			logger.Info("SyncTickets: cancelled after %d issues", n)
+			return SyncResult{Change: sink.finish()}, errSyncCancelled
 		}
-		b, _ := json.Marshal(payload)
-		f, _ := os.OpenFile("/home/menzelm/Projects/youtrack-helper/.cursor/debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if f != nil {
-			f.Write(b)
-			f.Write([]byte("\n"))
-			f.Close()
+		if err != nil {
+			return SyncResult{Change: sink.finish()}, userError("SyncTickets", err)
 		}
-	}()
-
-	// Parse tickets
-	yt.cachedTickets = []Ticket{}
-	for _, issue := range issues {
-		ticket := yt.parseTicket(issue, baseURL)
-		yt.cachedTickets = append(yt.cachedTickets, ticket)
-	}
-	// NDJSON debug: cache update
-	func() {
-		payload := map[string]interface{}{
-			"sessionId":    "debug-session",
-			"runId":        "run1",
-			"hypothesisId": "H3",
-			"location":     "youtrack_api.go:SyncTickets",
-			"message":      "cached_tickets_updated",
-			"data": map[string]interface{}{
-				"cached_count": len(yt.cachedTickets),
-			},
-			"timestamp": time.Now().UnixMilli(),
+	}
+	result.Change = sink.finish()
+	logger.Info("SyncTickets: received %d issues", sink.count())
+
+	if !delta {
+		// Keep what we have for projects that failed instead of dropping their tickets
+		failed := map[string]bool{}
+		for _, p := range result.Projects {
+			if p.Error != "" {
+				failed[p.Project] = true
+			}
 		}
-		b, _ := json.Marshal(payload)
-		f, _ := os.OpenFile("/home/menzelm/Projects/youtrack-helper/.cursor/debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if f != nil {
-			f.Write(b)
-			f.Write([]byte("\n"))
-			f.Close()
+		var gone []string
+		for _, t := range yt.store.Snapshot() {
+			if !sink.seen(t.ID) && !failed[t.Project()] {
+				gone = append(gone, t.ID)
+			}
+		}
+		if len(gone) > 0 {
+			result.Change.Removed = yt.store.Delete(gone...).Removed
 		}
-	}()
+	}
+	logger.Debug("SyncTickets: %d added, %d updated, %d removed", len(result.Change.Added), len(result.Change.Updated), len(result.Change.Removed))
 
-	return nil
+	return result, nil
 }
 
-func (yt *YouTrackAPI) parseTicket(issue map[string]interface{}, baseURL string) Ticket {
-	ticket := Ticket{}
-
-	// Extract basic fields
-	if id, ok := issue["idReadable"].(string); ok {
-		ticket.ID = id
-	}
-	if summary, ok := issue["summary"].(string); ok {
-		ticket.Summary = summary
-	}
-
-	// Construct URL
-	ticket.Url = fmt.Sprintf("%s/issues/%s", baseURL, ticket.ID)
-
-	// Parse custom fields
-	if customFields, ok := issue["customFields"].([]interface{}); ok {
-		for _, field := range customFields {
-			if fieldMap, ok := field.(map[string]interface{}); ok {
-				name, _ := fieldMap["name"].(string)
-				
-				if value, ok := fieldMap["value"]; ok {
-					switch name {
-					case "Type":
-						if typeVal, ok := value.(map[string]interface{}); ok {
-							if typeName, ok := typeVal["name"].(string); ok {
-								ticket.Type = typeName
-							}
-						}
-
-					case "Priority":
-						if priVal, ok := value.(map[string]interface{}); ok {
-							if priName, ok := priVal["name"].(string); ok {
-								ticket.Priority = priName
-							}
-						}
-
-					case "Sprints":
-						if sprints, ok := value.([]interface{}); ok {
-							for _, sprint := range sprints {
-								if sprintMap, ok := sprint.(map[string]interface{}); ok {
-									if sprintName, ok := sprintMap["name"].(string); ok {
-										ticket.Sprints = append(ticket.Sprints, sprintName)
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+// fetchPerProject runs one query per project concurrently, streaming all
+// tickets into sink. It fails only if the sync was cancelled or every
+// project failed.
+func (yt *YouTrackAPI) fetchPerProject(ctx context.Context, client *youtrack.Client, projects []string, since time.Time, sink *ticketSink, progress *syncProgressCounter) ([]ProjectSyncResult, error) {
+	results := make([]ProjectSyncResult, len(projects))
+	errs := make([]error, len(projects))
+
+	// The limiter in yt.http caps how many of these actually hit the server at once
+	var wg sync.WaitGroup
+	for i, project := range projects {
+		wg.Add(1)
+		go func(i int, project string) {
+			defer wg.Done()
+			query := syncQuery(fmt.Sprintf("project: %s", project), since)
+			results[i].Fetched, errs[i] = fetchTickets(ctx, client, query, sink, progress)
+		}(i, project)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		logger.Info("SyncTickets: cancelled during per-project sync")
+		return nil, errSyncCancelled
+	}
+
+	var firstErr error
+	failures := 0
+	for i, project := range projects {
+		results[i].Project = project
+		if errs[i] == nil {
+			logger.Info("SyncTickets: project %s: %d issues", project, results[i].Fetched)
+			continue
+		}
+		failures++
+		err := userError("SyncTickets "+project, errs[i])
+		results[i].Error = err.Error()
+		var apiErr *youtrack.APIError
+		if errors.As(err, &apiErr) {
+			results[i].Status = apiErr.Status
 		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if failures == len(projects) {
+		return results, firstErr
 	}
+	if failures > 0 {
+		logger.Warn("SyncTickets: %d of %d projects failed to sync", failures, len(projects))
+	}
+	return results, nil
+}
 
-	return ticket
+// syncQuery restricts query to issues updated since the given time, if set.
+// since must be in the profile timezone, see queryZone.
+func syncQuery(query string, since time.Time) string {
+	if since.IsZero() {
+		return query
+	}
+	// Parentheses keep "or" from binding only to the last project
+	return fmt.Sprintf("(%s) and updated: %s .. Today", query, since.Format(youtrackQueryTime))
 }
 
-func (yt *YouTrackAPI) GetCachedTickets() []Ticket {
-	return yt.cachedTickets
+// fetchTickets pages through all issues matching query, streaming them into
+// sink, and returns how many issues were received
+func fetchTickets(ctx context.Context, client *youtrack.Client, query string, sink *ticketSink, progress *syncProgressCounter) (int, error) {
+	n := 0
+	err := client.SyncIssues(ctx, query, youtrack.DefaultPageSize, func(issue youtrack.Issue) error {
+		sink.add(issue)
+		n++
+		return nil
+	}, progress.page)
+	return n, err
 }
 
-// normalizeBaseURL trims spaces and removes a trailing slash so /api/me is built correctly
-func normalizeBaseURL(baseURL string) string {
-	baseURL = strings.TrimSpace(baseURL)
-	baseURL = strings.TrimSuffix(baseURL, "/")
-	return baseURL
+// syncBatchSize is how many parsed tickets are collected before they are handed to the store
+const syncBatchSize = 100
+
+// ticketSink parses issues into tickets and upserts them into the store in
+// batches while a sync is running. It is safe for concurrent use.
+type ticketSink struct {
+	mu      sync.Mutex
+	store   *TicketStore
+	baseURL string
+	fields  fieldMapping
+	batch   []Ticket
+	ids     map[string]bool // every ticket ID received so far
+	change  StoreChange
+	touched map[string]bool // IDs already listed in change
 }
 
-// ValidateConnection tests the API connection with provided credentials
-func (yt *YouTrackAPI) ValidateConnection(ctx context.Context, baseURL, token string) error {
-	baseURL = normalizeBaseURL(baseURL)
-	if baseURL == "" {
-		return fmt.Errorf("Base URL is required.")
+func newTicketSink(store *TicketStore, baseURL string, fields fieldMapping) *ticketSink {
+	return &ticketSink{
+		store:   store,
+		baseURL: baseURL,
+		fields:  fields,
+		batch:   make([]Ticket, 0, syncBatchSize),
+		ids:     map[string]bool{},
+		touched: map[string]bool{},
 	}
-	apiURL := fmt.Sprintf("%s/api/users/me", baseURL)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-	if err != nil {
-		logger.Error("ValidateConnection: invalid URL: %v", err)
-		return fmt.Errorf("Invalid YouTrack URL.")
+// add parses one issue and flushes the batch once it is full
+func (s *ticketSink) add(issue youtrack.Issue) {
+	t := parseTicket(issue, s.baseURL, s.fields)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[t.ID] = true
+	s.batch = append(s.batch, t)
+	if len(s.batch) >= syncBatchSize {
+		s.flush()
 	}
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := yt.http.Do(req)
-	if err != nil {
-		logger.Error("ValidateConnection: request failed: %v", err)
-		return fmt.Errorf("Connection failed. Check your network and YouTrack URL.")
+// flush upserts the pending batch. Callers hold s.mu.
+func (s *ticketSink) flush() {
+	if len(s.batch) == 0 {
+		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		logger.Error("ValidateConnection: status=%d url=%s body=%s", resp.StatusCode, apiURL, string(body))
-		return fmt.Errorf("%s", userMessageForStatus(resp.StatusCode))
+	change := s.store.Upsert(s.batch...)
+	s.batch = s.batch[:0]
+	// Paging can return an issue twice; list each ID once, under the first kind seen
+	for _, id := range change.Added {
+		if !s.touched[id] {
+			s.touched[id] = true
+			s.change.Added = append(s.change.Added, id)
+		}
+	}
+	for _, id := range change.Updated {
+		if !s.touched[id] {
+			s.touched[id] = true
+			s.change.Updated = append(s.change.Updated, id)
+		}
 	}
+}
 
-	return nil
+// finish flushes the last batch and returns all changes applied so far
+func (s *ticketSink) finish() StoreChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flush()
+	return s.change
 }
 
-// GetCurrentUser fetches the current user information from YouTrack
-func (yt *YouTrackAPI) GetCurrentUser(ctx context.Context, baseURL, token string) (*User, error) {
-	baseURL = normalizeBaseURL(baseURL)
-	apiURL := fmt.Sprintf("%s/api/users/me?fields=id,name,email", baseURL)
+// seen reports whether the sync received the ticket with the given ID
+func (s *ticketSink) seen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ids[id]
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-	if err != nil {
-		logger.Error("GetCurrentUser: failed to create request: %v", err)
-		return nil, fmt.Errorf("Invalid YouTrack URL.")
-	}
+// count returns how many distinct tickets were received
+func (s *ticketSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.ids)
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/json")
+// syncProgressCounter aggregates page progress across concurrent fetches
+type syncProgressCounter struct {
+	mu      sync.Mutex
+	fn      func(SyncProgress)
+	pages   int
+	fetched int
+}
 
-	resp, err := yt.http.Do(req)
-	if err != nil {
-		logger.Error("GetCurrentUser: request failed: %v", err)
-		return nil, fmt.Errorf("Connection failed. Check your network and YouTrack URL.")
+func newSyncProgressCounter(fn func(SyncProgress)) *syncProgressCounter {
+	return &syncProgressCounter{fn: fn}
+}
+
+// page records one fetched page of n issues and reports the running totals
+func (c *syncProgressCounter) page(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages++
+	c.fetched += n
+	logger.Debug("SyncTickets: page %d returned %d issues (%d total)", c.pages, n, c.fetched)
+	if c.fn != nil {
+		c.fn(SyncProgress{Page: c.pages, PageSize: youtrack.DefaultPageSize, PageCount: n, Fetched: c.fetched})
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		logger.Error("GetCurrentUser: status=%d url=%s body=%s", resp.StatusCode, apiURL, string(body))
-		return nil, fmt.Errorf("%s", userMessageForStatus(resp.StatusCode))
+// parseTicket converts an issue into a Ticket, routing custom fields through the field mapping.
+func parseTicket(issue youtrack.Issue, baseURL string, fields fieldMapping) Ticket {
+	ticket := Ticket{
+		ID:      issue.IDReadable,
+		Summary: issue.Summary,
+		Url:     fmt.Sprintf("%s/issues/%s", baseURL, issue.IDReadable),
+		Created: issue.Created,
+		Updated: issue.Updated,
+	}
+	if issue.Reporter != nil {
+		ticket.Reporter = Person{Login: issue.Reporter.Login, FullName: issue.Reporter.DisplayName()}
+	}
+	// resolved is null until the issue enters a resolved state
+	if issue.Resolved != nil {
+		ticket.Resolved = true
+		ticket.ResolvedAt = *issue.Resolved
 	}
 
-	var user User
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		logger.Error("GetCurrentUser: decode error: %v", err)
-		return nil, fmt.Errorf("Invalid response from YouTrack. Try again later.")
+	for _, field := range issue.CustomFields {
+		if value, ok := fieldValueOf(field); ok {
+			fields.apply(&ticket, field.Name, value)
+		}
 	}
 
-	return &user, nil
+	return ticket
 }
 
-// GetProjects fetches available projects from YouTrack
-func (yt *YouTrackAPI) GetProjects(ctx context.Context, baseURL, token string) ([]Project, error) {
-	baseURL = normalizeBaseURL(baseURL)
-	apiURL := fmt.Sprintf("%s/api/admin/projects?fields=id,name,shortName,archived", baseURL)
+// SearchIssues runs query on the server and returns up to top matching
+// tickets. Unlike SyncTickets it covers every project the token can read
+// and leaves the cache alone.
+func (yt *YouTrackAPI) SearchIssues(ctx context.Context, query string, top int) ([]Ticket, error) {
+	cfg := yt.cm.GetConfig()
+	token := yt.cm.GetToken()
+	if cfg.BaseURL == "" || token == "" {
+		return nil, fmt.Errorf("YouTrack is not configured. Complete setup first.")
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	client := yt.client(cfg.BaseURL, token)
+	issues, err := client.ListIssues(ctx, query, 0, top)
 	if err != nil {
-		logger.Error("GetProjects: failed to create request: %v", err)
-		return nil, fmt.Errorf("Invalid YouTrack URL.")
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			return nil, errSearchCancelled
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			logger.Warn("SearchIssues: %q timed out", query)
+			return nil, fmt.Errorf("YouTrack search timed out. Try again later.")
+		}
+		return nil, userError("SearchIssues", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := yt.http.Do(req)
-	if err != nil {
-		logger.Error("GetProjects: request failed: %v", err)
-		return nil, fmt.Errorf("Connection failed. Check your network and YouTrack URL.")
+	fields := newFieldMapping(cfg.FieldMapping)
+	tickets := make([]Ticket, 0, len(issues))
+	for _, issue := range issues {
+		tickets = append(tickets, parseTicket(issue, client.BaseURL(), fields))
 	}
-	defer resp.Body.Close()
+	return tickets, nil
+}
+
+func (yt *YouTrackAPI) GetCachedTickets() []Ticket {
+	return yt.store.Snapshot()
+}
+
+// normalizeBaseURL trims spaces and removes a trailing slash so /api/me is built correctly
+func normalizeBaseURL(baseURL string) string {
+	return youtrack.NormalizeBaseURL(baseURL)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		logger.Error("GetProjects: status=%d url=%s body=%s", resp.StatusCode, apiURL, string(body))
-		return nil, fmt.Errorf("%s", userMessageForStatus(resp.StatusCode))
+// ValidateConnection tests the API connection with provided credentials
+func (yt *YouTrackAPI) ValidateConnection(ctx context.Context, baseURL, token string) error {
+	if normalizeBaseURL(baseURL) == "" {
+		return fmt.Errorf("Base URL is required.")
+	}
+	yt.mu.RLock()
+	transportErr := yt.transportErr
+	yt.mu.RUnlock()
+	if transportErr != nil {
+		var tErr *youtrack.TransportError
+		if errors.As(transportErr, &tErr) {
+			return &apiUserError{msg: fmt.Sprintf("Invalid %s setting: %v", tErr.Setting, tErr.Err), err: transportErr}
+		}
+		return transportErr
 	}
+	if err := yt.client(baseURL, token).ValidateConnection(ctx); err != nil {
+		return userError("ValidateConnection", err)
+	}
+	return nil
+}
 
-	var projects []Project
-	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
-		logger.Error("GetProjects: decode error: %v", err)
-		return nil, fmt.Errorf("Invalid response from YouTrack. Try again later.")
+// GetCurrentUser fetches the current user information from YouTrack
+func (yt *YouTrackAPI) GetCurrentUser(ctx context.Context, baseURL, token string) (*User, error) {
+	u, err := yt.client(baseURL, token).GetCurrentUser(ctx)
+	if err != nil {
+		return nil, userError("GetCurrentUser", err)
 	}
+	return &User{ID: u.ID, Login: u.Login, Name: u.DisplayName(), Email: u.Email, Type: u.Type}, nil
+}
 
-	return projects, nil
+// GetProjects fetches available projects from YouTrack
+func (yt *YouTrackAPI) GetProjects(ctx context.Context, baseURL, token string) ([]Project, error) {
+	projects, err := yt.client(baseURL, token).GetProjects(ctx)
+	if err != nil {
+		return nil, userError("GetProjects", err)
+	}
+	result := make([]Project, 0, len(projects))
+	for _, p := range projects {
+		result = append(result, Project{ID: p.ID, Name: p.Name, ShortName: p.ShortName, Archived: p.Archived})
+	}
+	return result, nil
 }
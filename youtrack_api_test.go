@@ -0,0 +1,518 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/zwoabier/youtrack-helper/internal/youtrack"
+	"github.com/zwoabier/youtrack-helper/internal/youtracktest"
+)
+
+// newTestAPI returns a YouTrackAPI configured for srv with the given
+// projects, backed by a mock keyring and a temporary home directory
+func newTestAPI(t *testing.T, srv *youtracktest.Server, cfg Config) *YouTrackAPI {
+	t.Helper()
+	keyring.MockInit()
+	t.Setenv("HOME", t.TempDir())
+
+	cm := NewConfigManager()
+	if err := cm.SaveToken(youtracktest.Token); err != nil {
+		t.Fatalf("saving token: %v", err)
+	}
+	cfg.BaseURL = srv.URL
+	cm.config = cfg
+	return NewYouTrackAPI(cm, NewTicketStore())
+}
+
+func ticketIDs(tickets []Ticket) []string {
+	ids := make([]string, 0, len(tickets))
+	for _, t := range tickets {
+		ids = append(ids, t.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func sorted(ids []string) []string {
+	out := append([]string(nil), ids...)
+	sort.Strings(out)
+	return out
+}
+
+// generatedIssues returns n minimal AGV issues for paging tests
+func generatedIssues(n int) []json.RawMessage {
+	issues := make([]json.RawMessage, n)
+	for i := range issues {
+		issues[i] = json.RawMessage(fmt.Sprintf(`{"idReadable":"AGV-%d","summary":"Issue %d","updated":1700000000000,"project":{"shortName":"AGV"}}`, i+1, i+1))
+	}
+	return issues
+}
+
+func TestSyncTickets(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		since    time.Time
+		setup    func(*youtracktest.Server)
+		timeout  time.Duration
+		wantIDs  []string
+		wantErr  string
+		requests int // expected /api/issues requests; 0 skips the check
+		report   []ProjectSyncResult
+	}{
+		{
+			name:    "full sync of all projects",
+			cfg:     Config{Projects: []string{"AGV", "JU"}},
+			wantIDs: []string{"AGV-951", "AGV-952", "AGV-953", "JU-17", "JU-18"},
+		},
+		{
+			name:    "single project",
+			cfg:     Config{Projects: []string{"JU"}},
+			wantIDs: []string{"JU-17", "JU-18"},
+		},
+		{
+			name:    "delta sync only fetches updated issues",
+			cfg:     Config{Projects: []string{"AGV", "JU"}},
+			since:   time.UnixMilli(1700400000000),
+			wantIDs: []string{"AGV-952", "JU-17"},
+		},
+		{
+			// The fake reads query dates in the profile zone (UTC-11), not the client's
+			name:    "delta sync uses the profile timezone",
+			cfg:     Config{Projects: []string{"AGV", "JU"}},
+			since:   time.UnixMilli(1700700000000).Add(-time.Hour),
+			wantIDs: []string{"JU-17"},
+		},
+		{
+			name:  "delta sync without a profile timezone fetches more, not less",
+			cfg:   Config{Projects: []string{"AGV", "JU"}},
+			since: time.UnixMilli(1700700000000).Add(-time.Hour),
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Path: "/api/users/me", Status: 404})
+			},
+			wantIDs: []string{"JU-17"},
+		},
+		{
+			name:    "per-project delta sync uses the profile timezone",
+			cfg:     Config{Projects: []string{"AGV", "JU"}, PerProjectSync: true},
+			since:   time.UnixMilli(1700700000000).Add(-time.Hour),
+			wantIDs: []string{"JU-17"},
+		},
+		{
+			name: "pages until a short page",
+			cfg:  Config{Projects: []string{"AGV"}},
+			setup: func(s *youtracktest.Server) {
+				s.SetIssues(generatedIssues(2*youtrack.DefaultPageSize + 7)...)
+			},
+			requests: 3,
+		},
+		{
+			name: "retries a rate-limited request",
+			cfg:  Config{Projects: []string{"AGV"}},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Path: "/api/issues", Status: 429, RetryAfter: "0", Times: 1})
+			},
+			wantIDs:  []string{"AGV-951", "AGV-952", "AGV-953"},
+			requests: 2,
+		},
+		{
+			name: "invalid token",
+			cfg:  Config{Projects: []string{"AGV"}},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Status: 401})
+			},
+			wantErr: "Invalid token",
+		},
+		{
+			name: "wrong base URL",
+			cfg:  Config{Projects: []string{"AGV"}},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Status: 404})
+			},
+			wantErr: "Check the base URL",
+		},
+		{
+			name: "server error after retries",
+			cfg:  Config{Projects: []string{"AGV"}},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Status: 500, RetryAfter: "0"})
+			},
+			wantErr:  "YouTrack server error",
+			requests: youtrack.DefaultMaxAttempts,
+		},
+		{
+			name: "malformed JSON",
+			cfg:  Config{Projects: []string{"AGV"}},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Malformed: true})
+			},
+			wantErr: "Invalid response from YouTrack",
+		},
+		{
+			name: "slow response is cancelled",
+			cfg:  Config{Projects: []string{"AGV"}},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Delay: 5 * time.Second})
+			},
+			timeout: 100 * time.Millisecond,
+			wantErr: errSyncCancelled.Error(),
+		},
+		{
+			name:    "no projects selected",
+			cfg:     Config{},
+			wantErr: "No projects selected",
+		},
+		{
+			name: "per-project sync keeps going when one project fails",
+			cfg:  Config{Projects: []string{"AGV", "JU"}, PerProjectSync: true},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Path: "/api/issues", Query: "project: JU", Status: 403})
+			},
+			wantIDs: []string{"AGV-951", "AGV-952", "AGV-953"},
+			report: []ProjectSyncResult{
+				{Project: "AGV", Fetched: 3},
+				{Project: "JU", Error: userMessageForStatus(403), Status: 403},
+			},
+		},
+		{
+			name: "per-project sync fails when every project fails",
+			cfg:  Config{Projects: []string{"AGV", "JU"}, PerProjectSync: true},
+			setup: func(s *youtracktest.Server) {
+				s.Inject(youtracktest.Fault{Status: 403})
+			},
+			wantErr: "Permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := youtracktest.NewServer()
+			defer srv.Close()
+			if tt.setup != nil {
+				tt.setup(srv)
+			}
+			yt := newTestAPI(t, srv, tt.cfg)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			result, err := yt.SyncTickets(ctx, SyncOptions{Since: tt.since})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SyncTickets() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SyncTickets() error = %v", err)
+			}
+			if tt.wantIDs != nil {
+				if got := ticketIDs(yt.store.Snapshot()); !reflect.DeepEqual(got, tt.wantIDs) {
+					t.Errorf("store = %v, want %v", got, tt.wantIDs)
+				}
+				if got := sorted(result.Change.Added); !reflect.DeepEqual(got, tt.wantIDs) {
+					t.Errorf("change.Added = %v, want %v", got, tt.wantIDs)
+				}
+			}
+			if tt.requests > 0 {
+				if got := srv.Requests("/api/issues"); got != tt.requests {
+					t.Errorf("/api/issues requests = %d, want %d", got, tt.requests)
+				}
+			}
+			if tt.report != nil && !reflect.DeepEqual(result.Projects, tt.report) {
+				t.Errorf("project report = %+v, want %+v", result.Projects, tt.report)
+			}
+		})
+	}
+}
+
+func TestSyncTicketsFullSyncRemovesMissingTickets(t *testing.T) {
+	srv := youtracktest.NewServer()
+	defer srv.Close()
+	yt := newTestAPI(t, srv, Config{Projects: []string{"AGV", "JU"}})
+	yt.store.Replace([]Ticket{{ID: "AGV-1", Summary: "deleted upstream"}, {ID: "AGV-951", Summary: "old summary"}})
+
+	result, err := yt.SyncTickets(context.Background(), SyncOptions{})
+	if err != nil {
+		t.Fatalf("SyncTickets() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Change.Removed, []string{"AGV-1"}) {
+		t.Errorf("Removed = %v, want [AGV-1]", result.Change.Removed)
+	}
+	if !reflect.DeepEqual(result.Change.Updated, []string{"AGV-951"}) {
+		t.Errorf("Updated = %v, want [AGV-951]", result.Change.Updated)
+	}
+
+	// A delta sync never removes anything
+	yt.store.Upsert(Ticket{ID: "AGV-2"})
+	result, err = yt.SyncTickets(context.Background(), SyncOptions{Since: time.Now()})
+	if err != nil {
+		t.Fatalf("delta SyncTickets() error = %v", err)
+	}
+	if _, ok := yt.store.Get("AGV-2"); !ok || len(result.Change.Removed) != 0 {
+		t.Errorf("delta sync removed tickets: %v", result.Change.Removed)
+	}
+}
+
+func TestParseTicket(t *testing.T) {
+	const baseURL = "https://youtrack.example.com"
+	tests := []struct {
+		name    string
+		issue   string
+		mapping map[string]string
+		want    Ticket
+	}{
+		{
+			name:  "minimal issue",
+			issue: `{"idReadable":"AGV-1","summary":"Minimal","created":1,"updated":2,"resolved":null}`,
+			want:  Ticket{ID: "AGV-1", Summary: "Minimal", Url: baseURL + "/issues/AGV-1", Created: 1, Updated: 2},
+		},
+		{
+			name: "standard fields",
+			issue: `{"idReadable":"AGV-2","summary":"Standard","resolved":1700600000000,
+				"reporter":{"login":"jdoe","fullName":"Jane Doe"},
+				"customFields":[
+					{"$type":"SingleEnumIssueCustomField","name":"Type","value":{"name":"Bug"}},
+					{"$type":"SingleEnumIssueCustomField","name":"Priority","value":{"name":"Critical"}},
+					{"$type":"StateIssueCustomField","name":"State","value":{"name":"Fixed","isResolved":true}},
+					{"$type":"SingleUserIssueCustomField","name":"Assignee","value":{"login":"jsmith","fullName":"John Smith"}},
+					{"$type":"MultiVersionIssueCustomField","name":"Sprints","value":[{"name":"Sprint 41"},{"name":"Sprint 42"}]}
+				]}`,
+			want: Ticket{
+				ID: "AGV-2", Summary: "Standard", Url: baseURL + "/issues/AGV-2",
+				Type: "Bug", Priority: "Critical", State: "Fixed",
+				Sprints:  []string{"Sprint 41", "Sprint 42"},
+				Assignee: Person{Login: "jsmith", FullName: "John Smith"},
+				Reporter: Person{Login: "jdoe", FullName: "Jane Doe"},
+				Resolved: true, ResolvedAt: 1700600000000,
+			},
+		},
+		{
+			name: "German field names",
+			issue: `{"idReadable":"AGV-3","summary":"Deutsch","customFields":[
+					{"$type":"SingleEnumIssueCustomField","name":"Typ","value":{"name":"Aufgabe"}},
+					{"$type":"SingleEnumIssueCustomField","name":"Priorität","value":{"name":"Normal"}}
+				]}`,
+			want: Ticket{ID: "AGV-3", Summary: "Deutsch", Url: baseURL + "/issues/AGV-3", Type: "Aufgabe", Priority: "Normal"},
+		},
+		{
+			name: "empty values are skipped",
+			issue: `{"idReadable":"AGV-4","summary":"Empty","reporter":null,"customFields":[
+					{"$type":"SingleUserIssueCustomField","name":"Assignee","value":null},
+					{"$type":"MultiVersionIssueCustomField","name":"Sprints","value":[]}
+				]}`,
+			want: Ticket{ID: "AGV-4", Summary: "Empty", Url: baseURL + "/issues/AGV-4"},
+		},
+		{
+			name: "unmapped fields go to CustomFields",
+			issue: `{"idReadable":"AGV-5","summary":"Custom","customFields":[
+					{"$type":"PeriodIssueCustomField","name":"Estimation","value":{"minutes":480,"presentation":"1d"}},
+					{"$type":"SimpleIssueCustomField","name":"Story points","value":5},
+					{"$type":"DateIssueCustomField","name":"Due Date","value":1701388800000},
+					{"$type":"TextIssueCustomField","name":"Notes","value":{"text":"Semikolon"}},
+					{"$type":"MultiUserIssueCustomField","name":"Reviewers","value":[{"login":"jdoe","fullName":"Jane Doe"},{"login":"ghost"}]}
+				]}`,
+			mapping: map[string]string{"Story points": "story_points"},
+			want: Ticket{
+				ID: "AGV-5", Summary: "Custom", Url: baseURL + "/issues/AGV-5",
+				CustomFields: map[string]FieldValue{
+					"Estimation":   {Kind: FieldPeriod, Values: []string{"1d"}, Number: 480},
+					"story_points": {Kind: FieldInteger, Values: []string{"5"}, Number: 5},
+					"Due Date":     {Kind: FieldDate, Values: []string{"2023-12-01"}, Number: 1701388800000},
+					"Notes":        {Kind: FieldText, Values: []string{"Semikolon"}},
+					"Reviewers":    {Kind: FieldUser, Multi: true, Values: []string{"Jane Doe", "ghost"}, Logins: []string{"jdoe", "ghost"}},
+				},
+			},
+		},
+		{
+			name: "mapping overrides the defaults",
+			issue: `{"idReadable":"AGV-6","summary":"Mapped","customFields":[
+					{"$type":"SingleEnumIssueCustomField","name":"Kind","value":{"name":"Epic"}},
+					{"$type":"SingleEnumIssueCustomField","name":"Type","value":{"name":"Bug"}}
+				]}`,
+			mapping: map[string]string{"Kind": AttrType, "Type": "legacy_type"},
+			want: Ticket{
+				ID: "AGV-6", Summary: "Mapped", Url: baseURL + "/issues/AGV-6", Type: "Epic",
+				CustomFields: map[string]FieldValue{"legacy_type": {Kind: FieldEnum, Values: []string{"Bug"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issue youtrack.Issue
+			if err := json.Unmarshal([]byte(tt.issue), &issue); err != nil {
+				t.Fatalf("decoding issue: %v", err)
+			}
+			got := parseTicket(issue, baseURL, newFieldMapping(tt.mapping))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTicket() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetProjects(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   *youtracktest.Fault
+		want    []string
+		wantErr string
+	}{
+		{name: "fixture projects", want: []string{"AGV", "JU", "OLD"}},
+		{name: "forbidden", fault: &youtracktest.Fault{Status: 403}, wantErr: "Permission denied"},
+		{name: "malformed", fault: &youtracktest.Fault{Malformed: true}, wantErr: "Invalid response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := youtracktest.NewServer()
+			defer srv.Close()
+			if tt.fault != nil {
+				srv.Inject(*tt.fault)
+			}
+			yt := newTestAPI(t, srv, Config{})
+
+			projects, err := yt.GetProjects(context.Background(), srv.URL, youtracktest.Token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetProjects() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetProjects() error = %v", err)
+			}
+			var got []string
+			for _, p := range projects {
+				got = append(got, p.ShortName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetProjects() = %v, want %v", got, tt.want)
+			}
+			if !projects[2].Archived {
+				t.Errorf("project OLD should be archived")
+			}
+		})
+	}
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    *User
+		wantErr string
+	}{
+		{
+			name:  "valid token",
+			token: youtracktest.Token,
+			want:  &User{ID: "1-1", Login: "jdoe", Name: "Jane Doe", Email: "jane.doe@example.com", Type: "Me"},
+		},
+		{name: "invalid token", token: "perm:wrong", wantErr: "Invalid token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := youtracktest.NewServer()
+			defer srv.Close()
+			yt := newTestAPI(t, srv, Config{})
+
+			user, err := yt.GetCurrentUser(context.Background(), srv.URL, tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetCurrentUser() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetCurrentUser() error = %v", err)
+			}
+			if !reflect.DeepEqual(user, tt.want) {
+				t.Errorf("GetCurrentUser() = %+v, want %+v", user, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConnection(t *testing.T) {
+	tests := []struct {
+		name       string
+		baseURL    func(srv *youtracktest.Server) string
+		token      string
+		fault      *youtracktest.Fault
+		wantErr    string
+		wantStatus int // expected APIError status reachable via errors.As
+	}{
+		{name: "valid", token: youtracktest.Token},
+		{name: "trailing slash", baseURL: func(s *youtracktest.Server) string { return s.URL + "/ " }, token: youtracktest.Token},
+		{name: "empty base URL", baseURL: func(*youtracktest.Server) string { return "  " }, token: youtracktest.Token, wantErr: "Base URL is required"},
+		{name: "invalid token", token: "perm:wrong", wantErr: "Invalid token", wantStatus: 401},
+		{name: "not found", token: youtracktest.Token, fault: &youtracktest.Fault{Status: 404}, wantErr: "Check the base URL", wantStatus: 404},
+		{name: "rate limited", token: youtracktest.Token, fault: &youtracktest.Fault{Status: 429, RetryAfter: "0"}, wantErr: "rate limiting", wantStatus: 429},
+		{name: "server error", token: youtracktest.Token, fault: &youtracktest.Fault{Status: 500, RetryAfter: "0"}, wantErr: "server error", wantStatus: 500},
+		{name: "recovers from one server error", token: youtracktest.Token, fault: &youtracktest.Fault{Status: 503, RetryAfter: "0", Times: 1}},
+		{name: "malformed JSON", token: youtracktest.Token, fault: &youtracktest.Fault{Malformed: true}, wantErr: "Invalid response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := youtracktest.NewServer()
+			defer srv.Close()
+			if tt.fault != nil {
+				srv.Inject(*tt.fault)
+			}
+			yt := newTestAPI(t, srv, Config{})
+			baseURL := srv.URL
+			if tt.baseURL != nil {
+				baseURL = tt.baseURL(srv)
+			}
+
+			err := yt.ValidateConnection(context.Background(), baseURL, tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateConnection() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateConnection() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantStatus != 0 {
+				var apiErr *youtrack.APIError
+				if !errors.As(err, &apiErr) || apiErr.Status != tt.wantStatus {
+					t.Errorf("errors.As(APIError) status = %v, want %d", apiErr, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConnectionSlowServer(t *testing.T) {
+	srv := youtracktest.NewServer()
+	defer srv.Close()
+	srv.Inject(youtracktest.Fault{Delay: 3 * time.Second})
+	yt := newTestAPI(t, srv, Config{HTTPTimeoutSeconds: 1})
+	if err := yt.ApplyConfig(yt.cm.GetConfig()); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+
+	start := time.Now()
+	err := yt.ValidateConnection(context.Background(), srv.URL, youtracktest.Token)
+	if err == nil || !strings.Contains(err.Error(), "did not respond in time") {
+		t.Fatalf("ValidateConnection() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2500*time.Millisecond {
+		t.Errorf("ValidateConnection() took %s, want about 1s", elapsed)
+	}
+}